@@ -0,0 +1,76 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// Arg holds information on a positional argument
+type Arg struct {
+	name        string
+	dataType    string
+	description string
+	isRequired  bool
+	// isVariadic marks the last argument that
+	// takes all the remaining words
+	isVariadic bool
+	// complete offers the values of the argument on Tab
+	complete CompleteFunc
+}
+
+// Name returns the name of the argument.
+func (a *Arg) Name() string {
+	return a.name
+}
+
+// Type returns the data type of the argument value.
+func (a *Arg) Type() string {
+	return a.dataType
+}
+
+// Description returns the description of the argument.
+func (a *Arg) Description() string {
+	return a.description
+}
+
+// IsRequired reports whether the argument must be passed.
+func (a *Arg) IsRequired() bool {
+	return a.isRequired
+}
+
+// IsVariadic reports whether the argument takes all the remaining words.
+func (a *Arg) IsVariadic() bool {
+	return a.isVariadic
+}
+
+// usage returns the argument as shown in the usage line,
+// <name> when required and [name] when optional.
+func (a *Arg) usage() string {
+	n := a.name
+	if a.isVariadic {
+		n += "..."
+	}
+	if a.isRequired {
+		return fmt.Sprintf("<%s>", n)
+	}
+	return fmt.Sprintf("[%s]", n)
+}
+
+func (a *Arg) String() string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 1, 8, 4, ' ', tabwriter.TabIndent)
+
+	var req = ""
+	if a.isRequired {
+		req = fmt.Sprintf("(required: %v)", a.isRequired)
+	}
+	fmt.Fprintf(w, "\t%s\t%s\n\t\t\t\t%s %s\n", a.usage(), a.dataType, a.description, req)
+	w.Flush()
+
+	return buf.String()
+}
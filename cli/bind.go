@@ -0,0 +1,307 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	valueType   = reflect.TypeOf((*FlagValue)(nil)).Elem()
+)
+
+// field is a struct field bound to a flag or an argument
+type field struct {
+	index []int
+	name  string
+}
+
+// NewStruct creates a command whose flags and arguments are defined by
+// the fields of a struct. The handler is a func(ctx context.Context, v *T) error
+// or a func(v *T) error, where T is the struct. See StructHandler for the tags.
+// Unlike NewContext, it returns an error when the name cannot be used.
+func (cli *CLI) NewStruct(name, shortDesc, description string, handler interface{}) (*Command, error) {
+	if err := nameError(cli.commands, name, name, true); err != nil {
+		return nil, err
+	}
+	cmd := cli.NewContext(name, shortDesc, description, nil)
+	if err := cmd.StructHandler(handler); err != nil {
+		delete(cli.commands, name)
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// NewStruct creates a subcommand whose flags and arguments are defined
+// by the fields of a struct, like CLI.NewStruct.
+func (c *Command) NewStruct(name, shortDesc, description string, handler interface{}) (*Command, error) {
+	if err := nameError(c.children, name, c.Path()+" "+name, false); err != nil {
+		return nil, err
+	}
+	cmd := c.NewContext(name, shortDesc, description, nil)
+	if err := cmd.StructHandler(handler); err != nil {
+		delete(c.children, name)
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// StructHandler adds the flags and the arguments defined by the fields of
+// a struct and sets a handler that gets a new instance of the struct
+// filled with their values. The handler is a func(ctx context.Context, v *T) error
+// or a func(v *T) error, where T is the struct.
+//
+// A field tagged with flag is bound to a flag and a field tagged with arg
+// to a positional argument, while the other fields are left alone. The
+// fields are string, int, float64, bool, time.Duration, time.Time,
+// net.IP, *net.IPNet, *url.URL, *regexp.Regexp or types whose pointer
+// is a FlagValue. Flags of type []string, []int and map[string]string
+// may be repeated, and a slice of the basic types makes a variadic
+// argument. Fields of the narrower int and float types reject the
+// values they cannot hold.
+// The other tags are alias, default, required, usage and env, the
+// environment variable read when the flag is not passed.
+//
+//	type getOptions struct {
+//		File    string `flag:"f" alias:"file" usage:"file name" required:"true"`
+//		Count   int    `flag:"n" alias:"num" default:"1" env:"APP_COUNT"`
+//		Verbose bool   `flag:"v" alias:"verbose"`
+//		Dir     string `arg:"dir" usage:"the directory"`
+//	}
+func (c *Command) StructHandler(handler interface{}) error {
+	h := reflect.ValueOf(handler)
+	t := h.Type()
+	if t.Kind() != reflect.Func || t.NumIn() < 1 || t.NumIn() > 2 || t.NumOut() != 1 ||
+		t.Out(0) != errorType || (t.NumIn() == 2 && t.In(0) != contextType) {
+		return fmt.Errorf("handler of command '%s' must be a func([context.Context, ]*struct) error, got %s", c.Path(), t)
+	}
+	ptr := t.In(t.NumIn() - 1)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("handler of command '%s' must take a pointer to a struct, got %s", c.Path(), ptr)
+	}
+
+	fields, err := c.bindStruct(ptr.Elem())
+	if err != nil {
+		return err
+	}
+	c.handler = func(ctx context.Context, flags Flags) error {
+		v := reflect.New(ptr.Elem())
+		fill(v.Elem(), fields, c.values(flags))
+		in := []reflect.Value{v}
+		if t.NumIn() == 2 {
+			in = []reflect.Value{reflect.ValueOf(ctx), v}
+		}
+		err, _ := h.Call(in)[0].Interface().(error)
+		return err
+	}
+	return nil
+}
+
+// bindStruct adds a flag or an argument for each tagged field of t
+func (c *Command) bindStruct(t reflect.Type) ([]field, error) {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		flagName, isFlag := sf.Tag.Lookup("flag")
+		argName, isArg := sf.Tag.Lookup("arg")
+		if !isFlag && !isArg {
+			continue
+		}
+		if isFlag && isArg {
+			return nil, fmt.Errorf("field %s is tagged both as a flag and as an argument", sf.Name)
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("field %s is not exported", sf.Name)
+		}
+
+		required, err := strconv.ParseBool(defaultTag(sf.Tag.Get("required"), "false"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s has an invalid required tag: %v", sf.Name, err)
+		}
+		usage := sf.Tag.Get("usage")
+
+		if isArg {
+			ft, variadic := sf.Type, false
+			if ft.Kind() == reflect.Slice {
+				ft, variadic = ft.Elem(), true
+			}
+			dataType := kindDataType(ft.Kind())
+			if dataType == "" {
+				return nil, fmt.Errorf("field %s has unsupported type %s", sf.Name, sf.Type)
+			}
+			if err := c.Arg(argName, dataType, usage, required, variadic); err != nil {
+				return nil, err
+			}
+			c.checkRange(argName, ft)
+			fields = append(fields, field{index: sf.Index, name: argName})
+			continue
+		}
+
+		value := builtinValue(sf.Type)
+		if value == nil && reflect.PtrTo(sf.Type).Implements(valueType) {
+			value = reflect.New(sf.Type).Interface().(FlagValue)
+		}
+		if value != nil {
+			if def := sf.Tag.Get("default"); def != "" {
+				if err := value.Set(def); err != nil {
+					return nil, fmt.Errorf("field %s has an invalid default: %v", sf.Name, err)
+				}
+			}
+			if err := c.Var(value, flagName, sf.Tag.Get("alias"), usage, required); err != nil {
+				return nil, err
+			}
+			if env := sf.Tag.Get("env"); env != "" {
+				c.Env(flagName, env)
+			}
+			fields = append(fields, field{index: sf.Index, name: flagName})
+			continue
+		}
+
+		dataType := kindDataType(sf.Type.Kind())
+		if dataType == "" {
+			return nil, fmt.Errorf("field %s has unsupported type %s", sf.Name, sf.Type)
+		}
+		def, err := defaultValue(dataType, sf.Tag.Get("default"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s has an invalid default: %v", sf.Name, err)
+		}
+		if err := c.Flag(flagName, sf.Tag.Get("alias"), dataType, def, usage, required); err != nil {
+			return nil, err
+		}
+		c.checkRange(flagName, sf.Type)
+		if env := sf.Tag.Get("env"); env != "" {
+			c.Env(flagName, env)
+		}
+		fields = append(fields, field{index: sf.Index, name: flagName})
+	}
+	return fields, nil
+}
+
+// checkRange adds a validator rejecting the values of the flag or the
+// argument name that overflow t, a field type narrower than the
+// int64 and float64 the values are converted to
+func (c *Command) checkRange(name string, t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Float32:
+	default:
+		return
+	}
+	zero := reflect.Zero(t)
+	overflows := func(v interface{}) bool {
+		switch x := v.(type) {
+		case int64:
+			return zero.OverflowInt(x)
+		case float64:
+			return zero.OverflowFloat(x)
+		}
+		return false
+	}
+	c.Validate(func(flags Flags) error {
+		value, ok := c.values(flags)[name]
+		if !ok {
+			return nil
+		}
+		list, variadic := value.([]interface{})
+		if !variadic {
+			list = []interface{}{value}
+		}
+		for _, v := range list {
+			if !overflows(v) {
+				continue
+			}
+			s := fmt.Sprint(v)
+			if f := c.getFlag(name); f != nil {
+				return &FlagError{Command: c.Path(), Flag: f.name, Value: s, Type: t.String(), Err: ErrInvalidValue}
+			}
+			return &ArgError{Command: c.Path(), Arg: name, Value: s, Type: t.String(), Err: ErrInvalidValue}
+		}
+		return nil
+	})
+}
+
+// fill sets the fields of the struct v to the values
+func fill(v reflect.Value, fields []field, values Values) {
+	for _, f := range fields {
+		value, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		fv := v.FieldByIndex(f.index)
+		list, ok := value.([]interface{})
+		if !ok {
+			set(fv, value)
+			continue
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(list), len(list))
+		for i, e := range list {
+			set(slice.Index(i), e)
+		}
+		fv.Set(slice)
+	}
+}
+
+// set stores a converted value into v
+func set(v reflect.Value, value interface{}) {
+	switch x := value.(type) {
+	case int64:
+		v.SetInt(x)
+	case int:
+		v.SetInt(int64(x))
+	case float64:
+		v.SetFloat(x)
+	case bool:
+		v.SetBool(x)
+	case string:
+		v.SetString(x)
+	case FlagValue:
+		if rv := reflect.ValueOf(x); rv.Kind() == reflect.Ptr && rv.Elem().Type() == v.Type() {
+			v.Set(rv.Elem())
+		}
+	default:
+		if rv := reflect.ValueOf(x); rv.IsValid() && rv.Type().AssignableTo(v.Type()) {
+			v.Set(rv)
+		}
+	}
+}
+
+// kindDataType returns the data type of the flags of a field kind
+func kindDataType(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float64"
+	case reflect.Bool:
+		return "bool"
+	}
+	return ""
+}
+
+// defaultValue converts the default tag to the type Flag expects
+func defaultValue(dataType, s string) (interface{}, error) {
+	switch dataType {
+	case "int":
+		return strconv.Atoi(defaultTag(s, "0"))
+	case "float64":
+		return strconv.ParseFloat(defaultTag(s, "0"), 64)
+	case "bool":
+		return strconv.ParseBool(defaultTag(s, "false"))
+	}
+	return s, nil
+}
+
+func defaultTag(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
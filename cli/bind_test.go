@@ -0,0 +1,159 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+type copyOptions struct {
+	Recursive bool     `flag:"r" alias:"recursive" usage:"copy directories"`
+	Depth     int      `flag:"d" alias:"depth" default:"3" env:"GO_ICLS_TEST_DEPTH"`
+	Ratio     float64  `flag:"x" default:"0.5"`
+	Mode      string   `flag:"m" alias:"mode" required:"true" usage:"file mode"`
+	Dst       string   `arg:"dst" required:"true" usage:"destination"`
+	Src       []string `arg:"src" usage:"sources"`
+	Level     logLevel `flag:"l" default:"warn"`
+	Exclude   []string `flag:"e" alias:"exclude"`
+	ignored   string
+}
+
+func TestCLI_NewStruct(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	var got *copyOptions
+	cp, err := c.NewStruct("cp", "copies", "copies files", func(ctx context.Context, o *copyOptions) error {
+		got = o
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	if f := cp.Lookup("mode"); f == nil || !f.IsRequired() || f.Description() != "file mode" {
+		t.Errorf("expected required flag 'm' with its usage, got %v", f)
+	}
+
+	if _, err := c.Execute("cp /tmp a 'b c' -r --mode 0644"); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	expected := &copyOptions{Recursive: true, Depth: 3, Ratio: 0.5, Mode: "0644", Dst: "/tmp", Src: []string{"a", "b c"}, Level: 2}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	if _, err := c.Execute("cp /tmp -m 0644 -l debug -e a --exclude b,c"); err != nil || got.Level != 0 ||
+		!reflect.DeepEqual(got.Exclude, []string{"a", "b", "c"}) {
+		t.Errorf("expected the debug level and 3 excludes, got %+v, '%v'", got, err)
+	}
+
+	os.Setenv("GO_ICLS_TEST_DEPTH", "7")
+	defer os.Unsetenv("GO_ICLS_TEST_DEPTH")
+	if _, err := c.Execute("cp /tmp -m 0644"); err != nil || got.Depth != 7 || got.Src != nil {
+		t.Errorf("expected the depth of the environment, got %+v, '%v'", got, err)
+	}
+	if _, err := c.Execute("cp /tmp -m 0644 -d 2"); err != nil || got.Depth != 2 {
+		t.Errorf("expected the passed depth to win over the environment, got %+v, '%v'", got, err)
+	}
+
+	os.Setenv("GO_ICLS_TEST_DEPTH", "deep")
+	if _, err := c.Execute("cp /tmp -m 0644"); !errors.Is(err, cli.ErrInvalidValue) {
+		t.Errorf("expected invalid value from the environment, got '%v'", err)
+	}
+}
+
+func TestCLI_NewStructErrors(t *testing.T) {
+	type badDefault struct {
+		N int `flag:"n" default:"one"`
+	}
+	type badType struct {
+		M map[string]int `flag:"m"`
+	}
+	var test = []struct {
+		description string
+		handler     interface{}
+	}{
+		{"not a func", 1},
+		{"no struct", func(s string) error { return nil }},
+		{"no error", func(o *copyOptions) {}},
+		{"not a context", func(s string, o *copyOptions) error { return nil }},
+		{"invalid default", func(o *badDefault) error { return nil }},
+		{"unsupported type", func(o *badType) error { return nil }},
+	}
+
+	for _, tt := range test {
+		c := cli.New()
+		if _, err := c.NewStruct("cmd", "", "", tt.handler); err == nil {
+			t.Errorf("%s: expected error, got none", tt.description)
+		}
+		if c.Command("cmd") != nil {
+			t.Errorf("%s: expected the command not to be added", tt.description)
+		}
+	}
+
+	c := cli.New()
+	remote := c.Simple("remote", "", "")
+	remote.Simple("add", "", "")
+	handler := func(o *copyOptions) error { return nil }
+	for _, create := range []func() (*cli.Command, error){
+		func() (*cli.Command, error) { return c.NewStruct("remote", "", "", handler) },
+		func() (*cli.Command, error) { return c.NewStruct("help", "", "", handler) },
+		func() (*cli.Command, error) { return remote.NewStruct("add", "", "", handler) },
+	} {
+		if _, err := create(); err == nil {
+			t.Errorf("expected an error for a name already in use")
+		}
+	}
+	if _, err := remote.NewStruct("q", "", "", handler); err != nil {
+		t.Errorf("expected a reserved word to name a subcommand, got '%v'", err)
+	}
+}
+
+func TestCLI_NewStructOverflow(t *testing.T) {
+	type narrowOptions struct {
+		Level int8    `flag:"n"`
+		Scale float32 `flag:"s"`
+		Ports []int16 `arg:"ports"`
+	}
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	var got *narrowOptions
+	if _, err := c.NewStruct("set", "sets", "sets the options", func(o *narrowOptions) error {
+		got = o
+		return nil
+	}); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+
+	var test = []struct {
+		line string
+		msg  string
+	}{
+		{"set 80 443 -n 127 -s 1.5", ""},
+		{"set -n 300", "invalid value '300' for flag 'n' of command 'set', expected int8"},
+		{"set -s 1e39", "invalid value '1e+39' for flag 's' of command 'set', expected float32"},
+		{"set 80 70000", "invalid value '70000' for argument 'ports', expected int16"},
+	}
+	for _, tt := range test {
+		got = nil
+		_, err := c.Execute(tt.line)
+		if tt.msg == "" {
+			if err != nil || got == nil || got.Level != 127 || got.Scale != 1.5 || len(got.Ports) != 2 {
+				t.Errorf("%s: expected the fields to be set, got %+v, '%v'", tt.line, got, err)
+			}
+			continue
+		}
+		if !errors.Is(err, cli.ErrInvalidValue) || err.Error() != tt.msg {
+			t.Errorf("%s: expected error \"%s\", got \"%v\"", tt.line, tt.msg, err)
+		}
+		if got != nil {
+			t.Errorf("%s: expected the handler not to run, got %+v", tt.line, got)
+		}
+	}
+}
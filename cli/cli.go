@@ -5,12 +5,17 @@
 package cli
 
 import (
-	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/RomanosTrechlis/go-icls/parse"
@@ -18,8 +23,29 @@ import (
 
 // CLI holds the closing channel and the defined commands.
 type CLI struct {
-	commands  map[string]*command
+	commands  map[string]*Command
 	closeChan chan struct{}
+	closeOnce sync.Once
+
+	// name is the program name shown in the usage
+	name string
+	// args are the arguments executed by Main
+	args []string
+	// historyFile keeps the lines entered in the terminal between sessions
+	historyFile string
+	// verbosity adds the -v, --verbose count flag to
+	// the commands, setting the level of the logger
+	verbosity bool
+	level     *slog.LevelVar
+	logger    *slog.Logger
+	in        io.Reader
+	out       io.Writer
+	err       io.Writer
+}
+
+// notifyInterrupt relays the interrupt signal to c while a command is executing
+var notifyInterrupt = func(c chan<- os.Signal) {
+	signal.Notify(c, os.Interrupt)
 }
 
 type Flags map[string]string
@@ -76,100 +102,287 @@ func StringValue(flag, alias string, flags Flags) (string, error) {
 	return "", fmt.Errorf("non existing flag: %s/%s", flag, alias)
 }
 
-// New creates a CLI struct.
-func New() *CLI {
-	return &CLI{
-		commands:  make(map[string]*command),
+// New creates a CLI struct. By default it reads from os.Stdin, writes
+// to os.Stdout and os.Stderr, uses os.Args[0] as the program name and
+// os.Args[1:] as the arguments of Main.
+func New(opts ...Option) *CLI {
+	cli := &CLI{
+		commands:  make(map[string]*Command),
 		closeChan: make(chan struct{}, 1),
+		name:      os.Args[0],
+		args:      os.Args[1:],
+		in:        os.Stdin,
+		out:       os.Stdout,
+		err:       os.Stderr,
 	}
+	for _, opt := range opts {
+		opt(cli)
+	}
+	cli.level = new(slog.LevelVar)
+	cli.level.Set(slog.LevelWarn)
+	cli.logger = slog.New(slog.NewTextHandler(cli.err, &slog.HandlerOptions{Level: cli.level}))
+	return cli
 }
 
-// Run begins reading from the Stdin for ever. Parses the
-// command given and apply it to the command handler.
+// Run begins reading from the input until quit or the end of the
+// input. Parses the command given and apply it to the command handler.
+// When the input is a terminal the line can be edited and previous
+// lines are recalled from the history.
 func (cli *CLI) Run() {
-	scanner := bufio.NewScanner(os.Stdin)
+	lines := cli.newLineReader()
+	defer lines.close()
 	go func() {
-		fmt.Fprintf(os.Stdout, "> ")
-		for scanner.Scan() {
-			exit, err := cli.Execute(scanner.Text())
+		defer cli.quit()
+		for {
+			line, err := lines.readLine("> ")
+			if err != nil {
+				return
+			}
+			exit, err := cli.executeInterruptible(func(ctx context.Context) (bool, error) {
+				return cli.ExecuteContext(ctx, line)
+			})
 			if exit {
-				cli.quit()
 				return
 			}
+			cli.report(err)
+		}
+	}()
+	<-cli.closeChan
+}
 
-			if err != nil && err.Error() != "" {
-				fmt.Fprintf(os.Stderr, "command failed: %v\n", err)
-			}
+// Main is the entry point of a program. When the program is given
+// arguments they are executed as a single command, otherwise the
+// interactive interface starts. It returns the exit status, which is
+// passed to os.Exit: 0 for success, the code of an ExitCoder returned
+// by the handler, 2 when the command is used wrongly, 130 when it is
+// interrupted and 1 for any other failure.
+//
+//	os.Exit(c.Main())
+func (cli *CLI) Main() int {
+	if len(cli.args) == 0 {
+		cli.Run()
+		return 0
+	}
+	_, err := cli.executeInterruptible(func(ctx context.Context) (bool, error) {
+		return cli.ExecuteArgsContext(ctx, cli.args)
+	})
+	cli.report(err)
+	return exitCode(err)
+}
+
+// report prints the error of an executed command
+func (cli *CLI) report(err error) {
+	var exit *ExitError
+	var reported *reportedError
+	switch {
+	case err == nil, errors.As(err, &reported):
+	case errors.Is(err, errInterrupted):
+		fmt.Fprintf(cli.err, "command interrupted\n")
+	case errors.As(err, &exit) && exit.Err == nil:
+	default:
+		fmt.Fprintf(cli.err, "command failed: %v\n", err)
+	}
+}
 
-			fmt.Fprintf(os.Stdout, "> ")
+// executeInterruptible calls execute and cancels its context when an
+// interrupt signal arrives, instead of terminating the program. Only the
+// first interrupt is caught, so a second one terminates the program as
+// usual when the command does not stop.
+func (cli *CLI) executeInterruptible(execute func(ctx context.Context) (bool, error)) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	notifyInterrupt(sig)
+	defer signal.Stop(sig)
+
+	interrupted := make(chan struct{})
+	go func() {
+		select {
+		case <-sig:
+			signal.Stop(sig)
+			close(interrupted)
+			cancel()
+		case <-ctx.Done():
 		}
 	}()
-	<-cli.closeChan
+
+	exit, err := execute(ctx)
+	select {
+	case <-interrupted:
+		if err != nil {
+			return exit, errInterrupted
+		}
+	default:
+	}
+	return exit, err
 }
 
 // Execute parses a string and applies the f function. Returns true for exiting.
 func (cli *CLI) Execute(textCmd string) (bool, error) {
+	return cli.ExecuteContext(context.Background(), textCmd)
+}
+
+// ExecuteContext is like Execute but passes ctx to the handler. The
+// context given to the handler is also cancelled when the CLI is closed.
+func (cli *CLI) ExecuteContext(ctx context.Context, textCmd string) (bool, error) {
 	trimedCmd := strings.Trim(textCmd, " ")
 	if trimedCmd == "" {
 		return false, nil
 	}
-	cmd, flags := cli.parse(trimedCmd)
-	if cmd == "quit" || cmd == "q" {
+	tokens, err := parse.Tokenize(trimedCmd)
+	if err != nil {
+		return false, err
+	}
+	return cli.execute(ctx, tokens)
+}
+
+// ExecuteArgs executes a command given as separate arguments, such as
+// os.Args[1:]. The arguments are used as they are, without splitting
+// or unquoting them again. Returns true for exiting.
+func (cli *CLI) ExecuteArgs(args []string) (bool, error) {
+	return cli.ExecuteArgsContext(context.Background(), args)
+}
+
+// ExecuteArgsContext is like ExecuteArgs but passes ctx to the handler.
+func (cli *CLI) ExecuteArgsContext(ctx context.Context, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	tokens := make([]parse.Token, len(args))
+	for i, a := range args {
+		tokens[i] = parse.Token{Value: a}
+	}
+	return cli.execute(ctx, tokens)
+}
+
+// execute runs the command of the tokens
+func (cli *CLI) execute(ctx context.Context, tokens []parse.Token) (bool, error) {
+	if len(tokens) > 0 && tokens[0].Value == completeCommand && !tokens[0].Literal {
+		return false, cli.completeArgs(tokens[1:])
+	}
+	r := parse.ParseTokens(tokens)
+	if r.Command == "quit" || r.Command == "q" {
 		return true, nil
 	}
-	if cli.Command(cmd) == nil && !help(flags) {
-		return false, fmt.Errorf("failed to find command '%s'", cmd)
+	cmd, args := cli.resolve(r.Command, r.Args)
+	if cmd != nil && (len(tokens) == 0 || tokens[0].Value != "help") {
+		// the words following the command path are parsed
+		// again, now that the flags of the command are known
+		r = parse.ParseFlags(tokens[commandLength(r, args):], cmd.spec())
+		args = r.Args
+	}
+	flags := Flags(r.Flags)
+	if cmd == nil && !help(flags) {
+		return false, &CommandError{Command: r.Command, Suggestion: suggest(r.Command, commandNames(cli.commands))}
 	}
 	if help(flags) {
-		cli.printHelp(cmd, flags)
+		cli.printHelp(cmd)
 		return false, nil
 	}
-	if _, ok := cli.validateFlags(cmd, flags); !ok {
-		cli.printHelp(cmd, flags)
-		return false, fmt.Errorf("")
+	if len(args) > 0 && len(cmd.children) > 0 && len(cmd.args) == 0 {
+		err := &CommandError{Command: cmd.Path() + " " + args[0]}
+		if name := suggest(args[0], commandNames(cmd.children)); name != "" {
+			err.Suggestion = cmd.Path() + " " + name
+		}
+		return false, err
+	}
+	cmd.collectRepeated(r.Occurrences, flags)
+	if err := cli.validateFlags(cmd, flags); err != nil {
+		return false, cli.usage(cmd, err)
+	}
+	if err := cmd.bindArgs(args, flags); err != nil {
+		return false, cli.usage(cmd, err)
+	}
+	if err := cmd.runValidators(flags); err != nil {
+		return false, cli.usage(cmd, err)
+	}
+	cli.setVerbosity(cmd, flags)
+	handler := cmd.handler
+	if handler == nil && len(cmd.children) > 0 {
+		cli.printHelp(cmd)
+		return false, nil
 	}
-	handler := cli.Command(cmd).handler
 	if handler == nil {
-		return false, fmt.Errorf("there is no handler for the command '%s'", cmd)
+		return false, fmt.Errorf("there is no handler for the command '%s'", cmd.Path())
+	}
+
+	ctx, cancel := context.WithCancel(cli.withIO(ctx))
+	defer cancel()
+	go func() {
+		select {
+		case <-cli.closeChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if err := handler(ctx, flags); err != nil {
+		return false, &HandlerError{Command: cmd.Path(), Err: err}
 	}
-	return false, handler(flags)
+	return false, nil
 }
 
 // New creates a command
-func (cli *CLI) New(name, shortDesc, description string, handler func(flags Flags) error) *command {
-	cmd := &command{
-		name:        name,
-		shortDesc:   shortDesc,
-		description: description,
-		flags:       make(map[string]*flag),
-		handler:     handler,
-	}
+func (cli *CLI) New(name, shortDesc, description string, handler func(flags Flags) error) *Command {
+	return cli.NewContext(name, shortDesc, description, withContext(handler))
+}
+
+// NewContext creates a command with a handler that takes a context,
+// which is cancelled when the execution is interrupted. Like the flag
+// package for a redefined flag, it panics when the name is already
+// used by another command, either as its name or as an alias, or
+// when it is one of help, quit and q.
+func (cli *CLI) NewContext(name, shortDesc, description string, handler func(ctx context.Context, flags Flags) error) *Command {
+	checkName(cli.commands, name, name, true)
+	cmd := newCommand(name, shortDesc, description, handler)
+	cmd.siblings = cli.commands
 	cli.commands[name] = cmd
+	if cli.verbosity {
+		cmd.addVerbosity()
+	}
 	return cmd
 }
 
 // New creates a command
-func (cli *CLI) Simple(name, shortDesc, description string) *command {
-	cmd := &command{
-		name:        name,
-		shortDesc:   shortDesc,
-		description: description,
-		handler:     emptyHandler(),
-		flags:       make(map[string]*flag),
-	}
-	cli.commands[name] = cmd
-	return cmd
+func (cli *CLI) Simple(name, shortDesc, description string) *Command {
+	return cli.New(name, shortDesc, description, emptyHandler())
 }
 
-// Command returns a command reference
-func (cli *CLI) Command(name string) *command {
-	cmd, ok := cli.commands[name]
-	if !ok {
+// Command returns a command reference. Subcommands are
+// reached by their path, e.g. "remote add".
+func (cli *CLI) Command(name string) *Command {
+	path := strings.Fields(name)
+	if len(path) == 0 {
+		path = []string{""}
+	}
+	cmd := find(cli.commands, path[0])
+	if cmd == nil {
 		return nil
 	}
+	for _, n := range path[1:] {
+		cmd = cmd.Command(n)
+		if cmd == nil {
+			return nil
+		}
+	}
 	return cmd
 }
 
+// Commands returns every command of the tree, depth first with
+// the commands of each level sorted by name.
+func (cli *CLI) Commands() []*Command {
+	var cmds []*Command
+	var walk func(m map[string]*Command)
+	walk = func(m map[string]*Command) {
+		for _, c := range sortedCommands(m) {
+			cmds = append(cmds, c)
+			walk(c.children)
+		}
+	}
+	walk(cli.commands)
+	return cmds
+}
+
 // HandlerFunc adds a handler to the specific command
 func (cli *CLI) HandlerFunc(commandName string, handler func(flags Flags) error) {
 	c := cli.Command(commandName)
@@ -177,89 +390,182 @@ func (cli *CLI) HandlerFunc(commandName string, handler func(flags Flags) error)
 		cli.New(commandName, "", "", handler)
 		return
 	}
-	c.handler = handler
+	c.Handler(handler)
 }
 
 // FlagValue returns the value from the flag list.
 func (cli *CLI) FlagValue(command, flag string, flags Flags) (interface{}, error) {
-	cmd := cli.Command(command)
-	f := cmd.getFlag(flag)
-	s := cli.getValueFromFlag(f, flags)
-	return conv(s, getDataTypeFunction(f.dataType))
+	if c := cli.Command(command); c != nil {
+		if f := c.getFlag(flag); f != nil && f.custom != nil {
+			return f.typedValue(flags)
+		}
+	}
+	s, dataType, err := cli.value(flag, command, flags)
+	if err != nil {
+		return nil, err
+	}
+	return conv(s, getDataTypeFunction(dataType))
 }
 
 // StringValue returns the string value from the flag list.
 func (cli *CLI) StringValue(flag, c string, flags Flags) string {
-	cmd := cli.Command(c)
-	f := cmd.getFlag(flag)
-	s := cli.getValueFromFlag(f, flags)
+	s, _, _ := cli.value(flag, c, flags)
 	return s
 }
 
 // BoolValue returns the bool value from the flag list.
 func (cli *CLI) BoolValue(flag, c string, flags Flags) (bool, error) {
-	cmd := cli.Command(c)
-	f := cmd.getFlag(flag)
-	s := cli.getValueFromFlag(f, flags)
-	return strconv.ParseBool(s)
+	s, dataType, err := cli.value(flag, c, flags)
+	if err != nil {
+		return false, err
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, cli.invalidValue(flag, c, s, dataType)
+	}
+	return b, nil
 }
 
 // IntValue returns the int value from the flag list.
 func (cli *CLI) IntValue(flag, c string, flags Flags) (int, error) {
-	cmd := cli.Command(c)
-	f := cmd.getFlag(flag)
-	s := cli.getValueFromFlag(f, flags)
+	s, dataType, err := cli.value(flag, c, flags)
+	if err != nil {
+		return 0, err
+	}
 	i, err := strconv.Atoi(s)
-	return i, err
+	if err != nil {
+		return 0, cli.invalidValue(flag, c, s, dataType)
+	}
+	return i, nil
 }
 
 // DoubleValue returns the float64 value from the flag list.
 func (cli *CLI) DoubleValue(flag, c string, flags Flags) (float64, error) {
+	s, dataType, err := cli.value(flag, c, flags)
+	if err != nil {
+		return 0.0, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0.0, cli.invalidValue(flag, c, s, dataType)
+	}
+	return f, nil
+}
+
+// ArgValue returns the value of a positional argument from the flag list.
+// For a variadic argument it returns the first of its values.
+func (cli *CLI) ArgValue(arg, c string, flags Flags) string {
+	values := cli.ArgValues(arg, c, flags)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// ArgValues returns all the values of a positional argument from the flag list.
+func (cli *CLI) ArgValues(arg, c string, flags Flags) []string {
 	cmd := cli.Command(c)
-	f := cmd.getFlag(flag)
-	if f == nil {
-		return 0.0, fmt.Errorf("couldn't find flag '%s' in command tree", flag)
+	if cmd == nil {
+		return nil
+	}
+	a := cmd.getArg(arg)
+	if a == nil {
+		return nil
+	}
+	s, ok := flags[a.name]
+	if !ok {
+		return nil
+	}
+	if !a.isVariadic {
+		return []string{s}
 	}
-	s := cli.getValueFromFlag(f, flags)
-	return strconv.ParseFloat(s, 64)
+	tokens, _ := parse.Tokenize(s)
+	values := make([]string, len(tokens))
+	for i, t := range tokens {
+		values[i] = t.Value
+	}
+	return values
 }
 
-func (cli *CLI) getValueFromFlag(flag *flag, flags Flags) string {
-	if s, ok := flags[flag.name]; ok {
-		if flag.dataType == "bool" {
-			return "true"
-		}
-		return s
+// value looks up name as a flag or as a positional argument of command c
+// and returns its value along with its data type.
+func (cli *CLI) value(name, c string, flags Flags) (string, string, error) {
+	cmd := cli.Command(c)
+	if cmd == nil {
+		return "", "", fmt.Errorf("couldn't find command '%s'", c)
 	}
-	if s, ok := flags[flag.alias]; ok {
-		if flag.dataType == "bool" {
-			return "true"
-		}
-		return s
+	if f := cmd.getFlag(name); f != nil {
+		return f.value(flags), f.dataType, nil
 	}
+	if a := cmd.getArg(name); a != nil {
+		return flags[a.name], a.dataType, nil
+	}
+	return "", "", fmt.Errorf("couldn't find flag '%s' in command tree", name)
+}
 
-	return flag.defaultValueToString()
+// invalidValue returns the error for a value of the flag or the
+// argument name of command c that does not match its data type.
+func (cli *CLI) invalidValue(name, c, value, dataType string) error {
+	if cmd := cli.Command(c); cmd != nil && cmd.getFlag(name) == nil && cmd.getArg(name) != nil {
+		return &ArgError{Command: c, Arg: name, Value: value, Type: dataType, Err: ErrInvalidValue}
+	}
+	return &FlagError{Command: c, Flag: name, Value: value, Type: dataType, Err: ErrInvalidValue}
 }
 
-func (cli *CLI) parse(cmd string) (string, Flags) {
+func (cli *CLI) parse(cmd string) (*parse.Result, error) {
 	cmd = strings.Trim(cmd, " ")
 	return parse.Parse(cmd)
 }
 
+// Close stops the interactive interface and cancels
+// the context of any running command.
+func (cli *CLI) Close() {
+	cli.quit()
+}
+
 func (cli *CLI) quit() {
-	close(cli.closeChan)
+	cli.closeOnce.Do(func() {
+		close(cli.closeChan)
+	})
+}
+
+// resolve walks the command tree starting from the top level command
+// name, descending into a subcommand for every leading word that names
+// one. It returns the deepest command found and the remaining words.
+func (cli *CLI) resolve(name string, words []string) (*Command, []string) {
+	cmd := find(cli.commands, name)
+	if cmd == nil {
+		return nil, words
+	}
+	for len(words) > 0 {
+		child := cmd.Command(words[0])
+		if child == nil {
+			break
+		}
+		cmd, words = child, words[1:]
+	}
+	return cmd, words
 }
 
-func (cli *CLI) printHelp(cmd string, flags Flags) {
-	if cmd == "" {
-		fmt.Fprintf(os.Stdout, "%v\n", cli)
+// commandLength returns the number of words of the command path
+// in r, which are followed by the words in args.
+func commandLength(r *parse.Result, args []string) int {
+	if r.Command == "" {
+		return 0
+	}
+	return 1 + len(r.Args) - len(args)
+}
+
+func (cli *CLI) printHelp(cmd *Command) {
+	if cmd == nil || cmd.name == "" {
+		fmt.Fprintf(cli.out, "%v\n", cli)
 		return
 	}
-	fmt.Fprintf(os.Stdout, "%v", cli.Command(cmd))
+	fmt.Fprintf(cli.out, "%v", cmd)
 }
 
 func (cli *CLI) String() string {
-	app := os.Args[0]
+	app := cli.name
 	buf := new(bytes.Buffer)
 	w := tabwriter.NewWriter(buf, 1, 8, 8, ' ', tabwriter.TabIndent)
 	fmt.Fprintf(w, "Usage:\n\n\t%s <command> [options]\n\n", app)
@@ -268,18 +574,18 @@ func (cli *CLI) String() string {
 	empty := cli.Command("")
 	if empty != nil {
 		fmt.Fprintf(w, "Flags:\n")
-		for _, flag := range empty.flags {
+		for _, flag := range empty.Flags() {
 			fmt.Fprintf(w, "%s\n", flag)
 		}
 	}
 
 	fmt.Fprintf(w, "Commands:\n")
-	for k, v := range cli.commands {
+	for _, v := range sortedCommands(cli.commands) {
 		// when it's an empty command skip the printing as a command
-		if k == "" {
+		if v.name == "" {
 			continue
 		}
-		fmt.Fprintf(w, "\t%s\t%s\n", k, v.shortDesc)
+		fmt.Fprintf(w, "\t%s\t%s\n", v.names(), v.shortDesc)
 	}
 	fmt.Fprintf(w, "\nUse \"%s <command> -h\" for more information about a command.", app)
 	w.Flush()
@@ -287,18 +593,60 @@ func (cli *CLI) String() string {
 	return buf.String()
 }
 
-func (cli *CLI) validateFlags(cmd string, flags Flags) (string, bool) {
-	c := cli.Command(cmd)
-	for _, f := range c.flags {
-		if !f.isRequired {
+// validateFlags checks the flags passed to the command, by name or by
+// alias, or set in their environment variables. The flags must be
+// defined on the command, unless it allows unknown flags, required
+// flags must have a value, the values must match the data type of the
+// flags and pass their validators, and the constraints of the command
+// must hold. Every problem found is reported in a ValidationError.
+func (cli *CLI) validateFlags(cmd *Command, flags Flags) error {
+	var errs []error
+	if !cmd.allowUnknownFlags {
+		errs = cmd.unknownFlags(flags)
+	}
+	for _, f := range cmd.Flags() {
+		v, passed := f.lookup(flags)
+		ok := passed
+		if !passed {
+			v, ok = f.envValue()
+		}
+		s := v
+		if !passed {
+			s = f.occurrence(v)
+		}
+		if f.isRequired && (!ok || (v == "" && !(passed && f.isBool()))) {
+			errs = append(errs, &FlagError{Command: cmd.Path(), Flag: f.name, Err: ErrMissingFlag})
+			continue
+		}
+		// bool flags passed without a value are true and
+		// the other empty values leave the default
+		if !ok || v == "" {
 			continue
 		}
-		// additionally the flag must have a value
-		if fl, ok := flags[f.name]; !ok || fl == "" {
-			return f.name, false
+		if _, err := f.parse(s); err != nil {
+			fe := &FlagError{Command: cmd.Path(), Flag: f.name, Value: v, Type: f.dataType, Err: ErrInvalidValue}
+			if f.custom != nil {
+				// the error of Set explains why the value is invalid
+				fe.Reason = err
+			}
+			errs = append(errs, fe)
+			continue
 		}
+		errs = append(errs, f.validate(cmd, v, flags)...)
 	}
-	return "", true
+	errs = append(errs, cmd.checkConstraints(flags)...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Command: cmd.Path(), Errors: errs}
+}
+
+// usage reports the error of a command that was used wrongly followed
+// by the help of the command, and marks the error as reported.
+func (cli *CLI) usage(cmd *Command, err error) error {
+	cli.report(err)
+	cli.printHelp(cmd)
+	return &reportedError{err}
 }
 
 func emptyHandler() func(flags Flags) error {
@@ -1,14 +1,20 @@
 package cli
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"testing"
+)
 
 func createCLI() *CLI {
 	c := New()
 	g := c.New("get", "get gets", "get gets", func(flags Flags) error {
 		return nil
 	})
-	g.StringFlag("f", "", "", "", true)
-	g.IntFlag("g", "", 1, "", false)
+	g.StringFlag("f", "file", "", "", true)
+	g.IntFlag("g", "int", 1, "", false)
 	return c
 }
 
@@ -25,9 +31,12 @@ func TestValidateFlags(t *testing.T) {
 		{"get", "there is no f and must be not ok", Flags{"g": "ggg"}, false},
 		{"get", "there is no f and must be not ok", Flags{"g": ""}, false},
 		{"get", "there is f and must be ok", Flags{"g": "", "f": "file"}, true},
+		{"get", "f is passed by its alias and must be ok", Flags{"file": "file"}, true},
+		{"get", "g is not an int and must be not ok", Flags{"f": "file", "g": "ggg"}, false},
+		{"get", "g is passed by its alias and must be ok", Flags{"f": "file", "int": "1"}, true},
 	}
 	for _, tt := range test {
-		_, ok := c.validateFlags(tt.name, tt.flags)
+		ok := c.validateFlags(c.Command(tt.name), tt.flags) == nil
 		if ok && !tt.ok {
 			t.Errorf("expected not ok, got ok for: %s", tt.description)
 		}
@@ -36,3 +45,68 @@ func TestValidateFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestExecuteInterruptible(t *testing.T) {
+	sig := make(chan chan<- os.Signal, 1)
+	notifyInterrupt = func(c chan<- os.Signal) {
+		sig <- c
+	}
+	defer func() {
+		notifyInterrupt = func(c chan<- os.Signal) {
+			signal.Notify(c, os.Interrupt)
+		}
+	}()
+
+	c := New()
+	c.NewContext("scan", "scans", "scans", func(ctx context.Context, flags Flags) error {
+		(<-sig) <- os.Interrupt
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	execute := func(line string) func(ctx context.Context) (bool, error) {
+		return func(ctx context.Context) (bool, error) {
+			return c.ExecuteContext(ctx, line)
+		}
+	}
+	_, err := c.executeInterruptible(execute("scan"))
+	if !errors.Is(err, errInterrupted) {
+		t.Errorf("expected interrupted error, got '%v'", err)
+	}
+
+	// a command that completes its work despite the interrupt succeeds
+	c.NewContext("save", "saves", "saves", func(ctx context.Context, flags Flags) error {
+		(<-sig) <- os.Interrupt
+		<-ctx.Done()
+		return nil
+	})
+	if _, err := c.executeInterruptible(execute("save")); err != nil {
+		t.Errorf("expected no error, got '%v'", err)
+	}
+
+	// a command that finishes is not affected
+	c.New("get", "gets", "gets", func(flags Flags) error {
+		return nil
+	})
+	go func() { <-sig }()
+	if _, err := c.executeInterruptible(execute("get")); err != nil {
+		t.Errorf("expected no error, got '%v'", err)
+	}
+}
+
+func TestCLI_CloseCancelsCommand(t *testing.T) {
+	c := New()
+	started := make(chan struct{})
+	c.NewContext("scan", "scans", "scans", func(ctx context.Context, flags Flags) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	go func() {
+		<-started
+		c.Close()
+	}()
+	_, err := c.ExecuteContext(context.Background(), "scan")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got '%v'", err)
+	}
+}
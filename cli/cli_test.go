@@ -5,6 +5,11 @@
 package cli_test
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/RomanosTrechlis/go-icls/cli"
@@ -106,7 +111,8 @@ func TestCLI_Execute(t *testing.T) {
 		{"get -l fail -r t", false, true},
 
 		{"get --int 1 -r t", false, false},
-		{"get --bool true -r t", false, false},
+		{"get --bool=true -r t", false, false},
+		{"get --bool true -r t", false, true},
 		{"get --float 1.0 -r t", false, false},
 		{"get --string success -r t", false, false},
 		{"get --string success", false, true},
@@ -125,3 +131,413 @@ func TestCLI_Execute(t *testing.T) {
 		}
 	}
 }
+
+func TestCLI_PositionalArgs(t *testing.T) {
+	c := cli.New()
+	var got []string
+	var count int
+	cp := c.New("cp", "copies", "copies files", func(flags cli.Flags) error {
+		got = c.ArgValues("src", "cp", flags)
+		got = append(got, c.ArgValue("dst", "cp", flags))
+		if _, ok := flags["count"]; !ok {
+			return nil
+		}
+		var err error
+		count, err = c.IntValue("count", "cp", flags)
+		return err
+	})
+	cp.StringArg("dst", "destination", true)
+	cp.IntArg("count", "number of copies", false)
+	cp.StringArgs("src", "source files", false)
+	cp.BoolFlag("v", "verbose", "")
+
+	var test = []struct {
+		line  string
+		got   []string
+		count int
+		err   bool
+	}{
+		{"cp out 2 a 'b c' -v", []string{"a", "b c", "out"}, 2, false},
+		{"cp out", []string{"out"}, 0, false},
+		{"cp", nil, 0, true},
+		{"cp out two", nil, 0, true},
+	}
+	for _, tt := range test {
+		got, count = nil, 0
+		_, err := c.Execute(tt.line)
+		if err == nil && tt.err {
+			t.Errorf("expected error, got no error: %s", tt.line)
+		}
+		if err != nil && !tt.err {
+			t.Errorf("expected no error, got '%v'", err)
+		}
+		if tt.err {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.got) || count != tt.count {
+			t.Errorf("%s: expected %v and %d, got %v and %d", tt.line, tt.got, tt.count, got, count)
+		}
+	}
+
+	if err := cp.Arg("more", "string", "", false, false); err == nil {
+		t.Errorf("expected error when adding an argument after a variadic one")
+	}
+	if err := cp.Flag("dst", "", "string", "", "", false); err == nil {
+		t.Errorf("expected error when a flag clashes with an argument")
+	}
+	if err := cp.Flag("n", "", "complex128", complex128(5), "", false); err == nil {
+		t.Errorf("expected error when a flag has a type without a converter")
+	}
+}
+
+func TestCLI_Subcommands(t *testing.T) {
+	c := cli.New()
+	var called string
+	remote := c.New("remote", "manages remotes", "manages remotes", nil)
+	add := remote.New("add", "adds a remote", "adds a remote", func(flags cli.Flags) error {
+		called = "remote add " + c.ArgValue("name", "remote add", flags)
+		return nil
+	})
+	add.StringArg("name", "remote name", true)
+	remote.New("rm", "removes a remote", "removes a remote", func(flags cli.Flags) error {
+		called = "remote rm"
+		return nil
+	})
+	url := remote.Simple("url", "remote urls", "remote urls")
+	url.New("set", "sets the url", "sets the url", func(flags cli.Flags) error {
+		called = "remote url set"
+		return nil
+	})
+
+	var test = []struct {
+		line   string
+		called string
+		err    bool
+	}{
+		{"remote add origin", "remote add origin", false},
+		{"remote rm", "remote rm", false},
+		{"remote url set", "remote url set", false},
+		{"remote url", "", false},
+		{"remote", "", false},
+		{"help remote add", "", false},
+		{"remote add -h", "", false},
+		{"remote bogus", "", true},
+		{"remote add", "", true},
+	}
+	for _, tt := range test {
+		called = ""
+		_, err := c.Execute(tt.line)
+		if err == nil && tt.err {
+			t.Errorf("expected error, got no error: %s", tt.line)
+		}
+		if err != nil && !tt.err {
+			t.Errorf("expected no error, got '%v'", err)
+		}
+		if called != tt.called {
+			t.Errorf("%s: expected '%s' to be called, got '%s'", tt.line, tt.called, called)
+		}
+	}
+
+	if c.Command("remote url set") == nil {
+		t.Errorf("expected to find command 'remote url set'")
+	}
+	if c.Command("remote nothing") != nil {
+		t.Errorf("expected not to find command 'remote nothing'")
+	}
+}
+
+func TestCommand_Alias(t *testing.T) {
+	c := cli.New()
+	var called string
+	list := c.New("list", "lists", "lists", func(flags cli.Flags) error {
+		called = "list"
+		return nil
+	})
+	if err := list.Alias("ls", "l"); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	remote := c.Simple("remote", "manages remotes", "manages remotes")
+	if err := remote.Alias("r"); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	rm := remote.New("remove", "removes", "removes", func(flags cli.Flags) error {
+		called = "remote remove"
+		return nil
+	})
+	if err := rm.Alias("rm", "ls"); err != nil {
+		t.Fatalf("expected no error for alias on another level, got '%v'", err)
+	}
+
+	var test = []struct {
+		line   string
+		called string
+	}{
+		{"list", "list"},
+		{"ls", "list"},
+		{"l", "list"},
+		{"r rm", "remote remove"},
+		{"remote ls", "remote remove"},
+	}
+	for _, tt := range test {
+		called = ""
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("expected no error, got '%v'", err)
+		}
+		if called != tt.called {
+			t.Errorf("%s: expected '%s' to be called, got '%s'", tt.line, tt.called, called)
+		}
+	}
+
+	if c.Command("r remove") != rm {
+		t.Errorf("expected to find command 'r remove'")
+	}
+	if err := remote.Alias("ls"); err == nil {
+		t.Errorf("expected error when alias is used by another command")
+	}
+	if err := remote.Alias("list"); err == nil {
+		t.Errorf("expected error when alias is the name of another command")
+	}
+	if err := remote.Alias("q"); err == nil {
+		t.Errorf("expected error when alias is a reserved word")
+	}
+	for _, create := range []func(){
+		func() { c.Simple("ls", "", "") },
+		func() { c.Simple("list", "", "") },
+		func() { remote.Simple("rm", "", "") },
+		func() { c.Simple("help", "", "") },
+		func() { c.Simple("quit", "", "") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic when a command shadows another one")
+				}
+			}()
+			create()
+		}()
+	}
+	if c.Command("ls") != list || remote.Command("rm") != rm {
+		t.Errorf("expected the commands to be left unchanged")
+	}
+}
+
+func TestCLI_Commands(t *testing.T) {
+	c := cli.New()
+	get := c.New("get", "get gets", "gets a file", nil)
+	get.StringFlag("f", "file", "", "file name", true)
+	get.StringArg("dir", "directory", false)
+	remote := c.Simple("remote", "manages remotes", "manages remotes")
+	remote.Simple("rm", "removes", "removes")
+	add := remote.Simple("add", "adds", "adds")
+	add.Alias("a")
+
+	var paths []string
+	for _, cmd := range c.Commands() {
+		paths = append(paths, cmd.Path())
+	}
+	expected := []string{"get", "remote", "remote add", "remote rm"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+
+	if add.Parent() != remote || remote.Parent() != nil {
+		t.Errorf("expected 'remote' to be the parent of 'add'")
+	}
+	if len(remote.Children()) != 2 || remote.Children()[0] != add {
+		t.Errorf("expected 'add' to be the first child of 'remote'")
+	}
+	if !reflect.DeepEqual(add.Aliases(), []string{"a"}) {
+		t.Errorf("expected aliases [a], got %v", add.Aliases())
+	}
+	if get.Name() != "get" || get.ShortDescription() != "get gets" || get.Description() != "gets a file" {
+		t.Errorf("unexpected command information for 'get'")
+	}
+
+	f := get.Lookup("file")
+	if f == nil || f.Name() != "f" || f.Type() != "string" || !f.IsRequired() || f.Description() != "file name" {
+		t.Fatalf("unexpected flag information for 'f'")
+	}
+	var names []string
+	for _, f := range get.Flags() {
+		names = append(names, f.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"f", "h"}) {
+		t.Errorf("expected flags [f h], got %v", names)
+	}
+	if args := get.Args(); len(args) != 1 || args[0].Name() != "dir" || args[0].IsRequired() {
+		t.Errorf("unexpected arguments for 'get': %v", args)
+	}
+}
+
+func TestCLI_ExecuteErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	get := c.New("get", "gets", "gets", func(flags cli.Flags) error {
+		return errFailed
+	})
+	get.StringFlag("f", "file", "", "file", true)
+	get.IntFlag("n", "num", 0, "number", false)
+	get.IntArg("id", "the id", false)
+
+	var test = []struct {
+		line   string
+		target error
+	}{
+		{"bogus", cli.ErrUnknownCommand},
+		{"get 1", cli.ErrMissingFlag},
+		{"get x -f a", cli.ErrInvalidValue},
+		{"get 1 2 -f a", cli.ErrTooManyArgs},
+		{"get -f a -n x", cli.ErrInvalidValue},
+		{"get -f a", cli.ErrHandler},
+		{"get -f a", errFailed},
+	}
+
+	for _, tt := range test {
+		_, err := c.Execute(tt.line)
+		if !errors.Is(err, tt.target) {
+			t.Errorf("%s: expected error '%v', got '%v'", tt.line, tt.target, err)
+		}
+	}
+
+	_, err := c.IntValue("n", "get", cli.Flags{"n": "x"})
+	var flagErr *cli.FlagError
+	if !errors.As(err, &flagErr) || flagErr.Flag != "n" || flagErr.Value != "x" {
+		t.Errorf("expected a flag error for 'n', got '%v'", err)
+	}
+}
+
+func TestCLI_ValidateFlags(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := cli.New(cli.WithOutput(out), cli.WithErrorOutput(out))
+	get := c.New("get", "gets", "gets", func(flags cli.Flags) error {
+		return nil
+	})
+	get.StringFlag("f", "file", "", "file", true)
+	get.StringFlag("d", "dir", "", "directory", true)
+	get.IntFlag("n", "num", 0, "number", false)
+
+	if _, err := c.Execute("get --file a --dir b --num 2"); err != nil {
+		t.Errorf("expected flags passed by alias to be valid, got '%v'", err)
+	}
+
+	_, err := c.Execute("get -d -n x")
+	var validation *cli.ValidationError
+	if !errors.As(err, &validation) || len(validation.Errors) != 3 {
+		t.Fatalf("expected a validation error with 3 errors, got '%v'", err)
+	}
+	for _, target := range []error{cli.ErrMissingFlag, cli.ErrInvalidValue} {
+		if !errors.Is(err, target) {
+			t.Errorf("expected error to match '%v', got '%v'", target, err)
+		}
+	}
+	expected := "command failed: invalid flags of command 'get':\n" +
+		"\tmissing required flag 'd'\n" +
+		"\tmissing required flag 'f'\n" +
+		"\tinvalid value 'x' for flag 'n', expected int\n" +
+		"usage: get"
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("expected the errors before the help, got:\n%s", out.String())
+	}
+}
+
+func TestCLI_UnknownFlags(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	get := c.New("get", "gets", "gets", func(flags cli.Flags) error {
+		return nil
+	})
+	get.BoolFlag("v", "verbose", "verbose output")
+	var passed cli.Flags
+	exec := c.New("exec", "executes", "executes a program", func(flags cli.Flags) error {
+		passed = flags
+		return nil
+	})
+	exec.AllowUnknownFlags(true)
+	remote := c.Simple("remote", "remotes", "manages remotes")
+	remote.New("add", "adds", "adds a remote", func(flags cli.Flags) error {
+		return nil
+	})
+
+	var test = []struct {
+		line   string
+		target error
+		msg    string
+	}{
+		{"get --verbos", cli.ErrUnknownFlag, "unknown flag 'verbos' of command 'get', did you mean 'verbose'?"},
+		{"get -x", cli.ErrUnknownFlag, "unknown flag 'x' of command 'get'"},
+		{"gte", cli.ErrUnknownCommand, "failed to find command 'gte', did you mean 'get'?"},
+		{"remote ad", cli.ErrUnknownCommand, "failed to find command 'remote ad', did you mean 'remote add'?"},
+		{"deploy", cli.ErrUnknownCommand, "failed to find command 'deploy'"},
+	}
+	for _, tt := range test {
+		_, err := c.Execute(tt.line)
+		if !errors.Is(err, tt.target) {
+			t.Errorf("%s: expected error '%v', got '%v'", tt.line, tt.target, err)
+		}
+		if err != nil && err.Error() != tt.msg {
+			t.Errorf("%s: expected message \"%s\", got \"%s\"", tt.line, tt.msg, err)
+		}
+	}
+
+	if _, err := c.Execute("get --verbose"); err != nil {
+		t.Errorf("expected no error for a defined flag, got '%v'", err)
+	}
+	if _, err := c.Execute("exec -la --color always"); err != nil {
+		t.Errorf("expected unknown flags to pass through, got '%v'", err)
+	}
+	if passed["la"] != "" || passed["color"] != "always" {
+		t.Errorf("expected the unknown flags to reach the handler, got %v", passed)
+	}
+}
+
+func TestCLI_GNUFlags(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	var (
+		v, a    *cli.BoolFlagRef
+		g       *cli.IntFlagRef
+		o       *cli.StringFlagRef
+		f       *cli.ArgRef[[]string]
+		verbose bool
+		all     bool
+		offset  int
+		output  string
+		files   []string
+	)
+	ls := c.New("ls", "lists", "lists files", func(flags cli.Flags) error {
+		verbose, all, offset = v.Get(flags), a.Get(flags), g.Get(flags)
+		output, files = o.Get(flags), f.Get(flags)
+		return nil
+	})
+	v = ls.BoolFlag("v", "verbose", "verbose output")
+	a = ls.BoolFlag("a", "all", "all files")
+	g = ls.IntFlag("g", "offset", 0, "offset", false)
+	o = ls.StringFlag("o", "output", "", "output file", false)
+	f = ls.StringArgs("files", "the files", false)
+
+	var test = []struct {
+		line    string
+		verbose bool
+		all     bool
+		offset  int
+		output  string
+		files   []string
+	}{
+		{"ls -va x", true, true, 0, "", []string{"x"}},
+		{"ls --offset=3 --output out x y", false, false, 3, "out", []string{"x", "y"}},
+		{"ls -vaoout -g -5", true, true, -5, "out", nil},
+		{"ls x -v y", true, false, 0, "", []string{"x", "y"}},
+		{"ls -v maybe", true, false, 0, "", []string{"maybe"}},
+		{"ls --verbose=false -a -- -g", false, true, 0, "", []string{"-g"}},
+		{"ls -v true", true, false, 0, "", []string{"true"}},
+	}
+	for _, tt := range test {
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+			continue
+		}
+		if verbose != tt.verbose || all != tt.all || offset != tt.offset || output != tt.output ||
+			!reflect.DeepEqual(files, tt.files) {
+			t.Errorf("%s: expected %v %v %d '%s' %v, got %v %v %d '%s' %v", tt.line, tt.verbose, tt.all,
+				tt.offset, tt.output, tt.files, verbose, all, offset, output, files)
+		}
+	}
+}
@@ -5,9 +5,15 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
 )
 
 // help checks if there are flags -h or --help and return true
@@ -15,31 +21,293 @@ func help(flags Flags) bool {
 	return checkForKeysInMap(flags, "h", "help")
 }
 
-// command is defined by the user and holds the all
+// Command is defined by the user and holds the all
 // the information necessary to run when it is called
-type command struct {
+type Command struct {
 	name        string
+	aliases     []string
 	shortDesc   string
 	description string
 	// flags is a map of flag objects that
 	// contain information on them
-	flags map[string]*flag
+	flags map[string]*Flag
+	// args holds the positional arguments in the order
+	// they are expected after the command name
+	args []*Arg
 	// handler is the function executed when the command is called
-	handler func(flags Flags) error
+	handler func(ctx context.Context, flags Flags) error
+	// parent is the command that owns this one,
+	// nil for the top level commands
+	parent *Command
+	// children holds the subcommands by name
+	children map[string]*Command
+	// siblings is the map the command is registered in,
+	// either the CLI commands or the parent's children
+	siblings map[string]*Command
+	// allowUnknownFlags passes the flags that are not
+	// defined to the handler instead of rejecting them
+	allowUnknownFlags bool
+	// constraints relate the flags, e.g. making them mutually exclusive
+	constraints []constraint
+	// validators check the command before the handler runs
+	validators []func(flags Flags) error
+	// verbosity gives the command and its subcommands
+	// the -v, --verbose flag of a CLI WithVerbosity
+	verbosity bool
+}
+
+func newCommand(name, shortDesc, description string, handler func(ctx context.Context, flags Flags) error) *Command {
+	return &Command{
+		name:        name,
+		shortDesc:   shortDesc,
+		description: description,
+		flags: map[string]*Flag{
+			"h": {
+				name:         "h",
+				alias:        "help",
+				dataType:     "bool",
+				defaultValue: false,
+				description:  "prints out information about the command",
+				isRequired:   false},
+		},
+		handler:  handler,
+		children: make(map[string]*Command),
+	}
+}
+
+// Name returns the name of the command.
+func (c *Command) Name() string {
+	return c.name
+}
+
+// Aliases returns the alternative names of the command.
+func (c *Command) Aliases() []string {
+	return append([]string(nil), c.aliases...)
+}
+
+// ShortDescription returns the description shown in the command listing.
+func (c *Command) ShortDescription() string {
+	return c.shortDesc
+}
+
+// Description returns the description shown in the command help.
+func (c *Command) Description() string {
+	return c.description
+}
+
+// Parent returns the command that owns this one, nil for a top level command.
+func (c *Command) Parent() *Command {
+	return c.parent
+}
+
+// Flags returns the flags of the command sorted by name.
+func (c *Command) Flags() []*Flag {
+	keys := make([]string, 0, len(c.flags))
+	for k := range c.flags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	flags := make([]*Flag, len(keys))
+	for i, k := range keys {
+		flags[i] = c.flags[k]
+	}
+	return flags
+}
+
+// Lookup returns the flag with the given name or alias, nil if there is none.
+func (c *Command) Lookup(name string) *Flag {
+	return c.getFlag(name)
+}
+
+// Args returns the positional arguments of the command in order.
+func (c *Command) Args() []*Arg {
+	return append([]*Arg(nil), c.args...)
+}
+
+// Children returns the subcommands of the command sorted by name.
+func (c *Command) Children() []*Command {
+	return sortedCommands(c.children)
+}
+
+func sortedCommands(m map[string]*Command) []*Command {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	cmds := make([]*Command, len(names))
+	for i, k := range names {
+		cmds[i] = m[k]
+	}
+	return cmds
+}
+
+// New creates a subcommand of the command
+func (c *Command) New(name, shortDesc, description string, handler func(flags Flags) error) *Command {
+	return c.NewContext(name, shortDesc, description, withContext(handler))
 }
 
-func (c *command) Handler(h func(flags Flags) error) {
+// NewContext creates a subcommand of the command with a handler that
+// takes a context, which is cancelled when the execution is interrupted.
+// It panics when the name is already used by another subcommand, like
+// CLI.NewContext.
+func (c *Command) NewContext(name, shortDesc, description string, handler func(ctx context.Context, flags Flags) error) *Command {
+	checkName(c.children, name, c.Path()+" "+name, false)
+	cmd := newCommand(name, shortDesc, description, handler)
+	cmd.parent = c
+	cmd.siblings = c.children
+	c.children[name] = cmd
+	if c.verbosity {
+		cmd.addVerbosity()
+	}
+	return cmd
+}
+
+// Simple creates a subcommand of the command that does nothing when called
+func (c *Command) Simple(name, shortDesc, description string) *Command {
+	return c.New(name, shortDesc, description, emptyHandler())
+}
+
+// Command returns a subcommand reference by name or alias
+func (c *Command) Command(name string) *Command {
+	return find(c.children, name)
+}
+
+// Alias adds alternative names to the command. It fails when
+// another command on the same level already uses one of them,
+// or when a top level command is given a reserved word.
+func (c *Command) Alias(aliases ...string) error {
+	for _, a := range aliases {
+		if c.parent == nil && isReserved(a) {
+			return fmt.Errorf("alias '%s' of command '%s' is a reserved name", a, c.Path())
+		}
+		if other := find(c.siblings, a); other != nil && other != c {
+			return fmt.Errorf("alias '%s' of command '%s' is already used by command '%s'", a, c.Path(), other.Path())
+		}
+	}
+	for _, a := range aliases {
+		if !c.hasName(a) {
+			c.aliases = append(c.aliases, a)
+		}
+	}
+	return nil
+}
+
+// hasName checks if name is the name or one of the aliases of the command
+func (c *Command) hasName(name string) bool {
+	if c.name == name {
+		return true
+	}
+	for _, a := range c.aliases {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the name of the command followed by its aliases
+func (c *Command) names() string {
+	return strings.Join(append([]string{c.name}, c.aliases...), ", ")
+}
+
+// reserved holds the words that the CLI handles itself
+// before looking up a top level command
+var reserved = []string{"help", "quit", "q"}
+
+// isReserved checks if name is one of the reserved words
+func isReserved(name string) bool {
+	for _, r := range reserved {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nameError returns an error when name is already used by a command in
+// m, which would otherwise be shadowed by the command at path, or when
+// a top level command is named after a reserved word
+func nameError(m map[string]*Command, name, path string, top bool) error {
+	if top && isReserved(name) {
+		return fmt.Errorf("command '%s' uses the reserved name '%s'", path, name)
+	}
+	if other := find(m, name); other != nil {
+		return fmt.Errorf("command '%s' is already defined by command '%s'", path, other.Path())
+	}
+	return nil
+}
+
+// checkName panics with the error of nameError
+func checkName(m map[string]*Command, name, path string, top bool) {
+	if err := nameError(m, name, path, top); err != nil {
+		panic(err.Error())
+	}
+}
+
+// find looks up a command in m by name first and then by alias
+func find(m map[string]*Command, name string) *Command {
+	if cmd, ok := m[name]; ok {
+		return cmd
+	}
+	for _, cmd := range m {
+		if cmd.hasName(name) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Path returns the names of the command and its
+// parents separated by spaces, e.g. "remote add"
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.Path() + " " + c.name
+}
+
+func (c *Command) Handler(h func(flags Flags) error) {
+	c.handler = withContext(h)
+}
+
+// ContextHandler sets a handler that takes a context. The context is
+// cancelled when the user interrupts the command or the CLI is closed.
+func (c *Command) ContextHandler(h func(ctx context.Context, flags Flags) error) {
 	c.handler = h
 }
 
+// withContext adapts a handler that does not take a context
+func withContext(h func(flags Flags) error) func(ctx context.Context, flags Flags) error {
+	if h == nil {
+		return nil
+	}
+	return func(_ context.Context, flags Flags) error {
+		return h(flags)
+	}
+}
+
 // Flag add a new flag in the command struct
-func (c *command) Flag(name, alias, dataType string, defaultValue interface{}, description string, isRequired bool) error {
+func (c *Command) Flag(name, alias, dataType string, defaultValue interface{}, description string, isRequired bool) error {
+	dataType = canonicalType(dataType)
+	if getDataTypeFunction(dataType) == nil {
+		return fmt.Errorf("flag %s/%s has unsupported type %s", name, alias, dataType)
+	}
 	if defaultValue != nil && reflect.TypeOf(defaultValue).String() != dataType {
 		return fmt.Errorf("default value %v, is of type %s, expecting type %s", defaultValue,
 			reflect.TypeOf(defaultValue).String(), dataType)
 	}
 
-	flag := &flag{
+	if c.getArg(name) != nil || c.getArg(alias) != nil {
+		return fmt.Errorf("flag %s/%s clashes with a positional argument", name, alias)
+	}
+	if err := c.replaceVerbosity(name, alias); err != nil {
+		return err
+	}
+
+	flag := &Flag{
 		name:         name,
 		alias:        alias,
 		dataType:     dataType,
@@ -52,27 +320,188 @@ func (c *command) Flag(name, alias, dataType string, defaultValue interface{}, d
 	return nil
 }
 
-// IntFlag adds an integer type value flag to command.
-func (c *command) IntFlag(name, alias string, defaultValue int, description string, isRequired bool) {
-	c.Flag(name, alias, "int", defaultValue, description, isRequired)
+// IntFlag adds an integer type value flag to command
+// and returns a handle reading its value.
+func (c *Command) IntFlag(name, alias string, defaultValue int, description string, isRequired bool) *IntFlagRef {
+	return newFlagRef[int](c, c.Flag(name, alias, "int", defaultValue, description, isRequired), name)
+}
+
+// FloatFlag adds a float type value flag to command
+// and returns a handle reading its value.
+func (c *Command) FloatFlag(name, alias string, defaultValue float64, description string, isRequired bool) *FloatFlagRef {
+	return newFlagRef[float64](c, c.Flag(name, alias, "float64", defaultValue, description, isRequired), name)
+}
+
+// BoolFlag adds a bool type value flag to command
+// and returns a handle reading its value.
+func (c *Command) BoolFlag(name, alias string, description string) *BoolFlagRef {
+	return newFlagRef[bool](c, c.Flag(name, alias, "bool", false, description, false), name)
+}
+
+// StringFlag adds an String type value flag to command
+// and returns a handle reading its value.
+func (c *Command) StringFlag(name, alias string, defaultValue string, description string, isRequired bool) *StringFlagRef {
+	return newFlagRef[string](c, c.Flag(name, alias, "string", defaultValue, description, isRequired), name)
+}
+
+// Env sets the environment variable that holds the value
+// of the flag when it is not passed to the command.
+func (c *Command) Env(flag, variable string) error {
+	f := c.getFlag(flag)
+	if f == nil {
+		return fmt.Errorf("command '%s' has no flag '%s'", c.Path(), flag)
+	}
+	f.env = variable
+	return nil
+}
+
+// Arg adds a positional argument in the command struct. Arguments are
+// filled in the order they are added from the words that follow the
+// command name. A variadic argument takes all the remaining words and
+// must be the last one.
+func (c *Command) Arg(name, dataType, description string, isRequired, isVariadic bool) error {
+	dataType = canonicalType(dataType)
+	if getDataTypeFunction(dataType) == nil {
+		return fmt.Errorf("argument %s has unsupported type %s", name, dataType)
+	}
+	if c.getArg(name) != nil || c.getFlag(name) != nil {
+		return fmt.Errorf("argument %s is already defined", name)
+	}
+	if n := len(c.args); n > 0 {
+		last := c.args[n-1]
+		if last.isVariadic {
+			return fmt.Errorf("argument %s follows variadic argument %s", name, last.name)
+		}
+		if isRequired && !last.isRequired {
+			return fmt.Errorf("required argument %s follows optional argument %s", name, last.name)
+		}
+	}
+
+	c.args = append(c.args, &Arg{
+		name:        name,
+		dataType:    dataType,
+		description: description,
+		isRequired:  isRequired,
+		isVariadic:  isVariadic,
+	})
+	return nil
+}
+
+// StringArg adds a string type positional argument to command
+// and returns a handle reading its value.
+func (c *Command) StringArg(name, description string, isRequired bool) *ArgRef[string] {
+	return newArgRef[string](c, c.Arg(name, "string", description, isRequired, false), name)
+}
+
+// IntArg adds an integer type positional argument to command
+// and returns a handle reading its value.
+func (c *Command) IntArg(name, description string, isRequired bool) *ArgRef[int] {
+	return newArgRef[int](c, c.Arg(name, "int", description, isRequired, false), name)
+}
+
+// FloatArg adds a float type positional argument to command
+// and returns a handle reading its value.
+func (c *Command) FloatArg(name, description string, isRequired bool) *ArgRef[float64] {
+	return newArgRef[float64](c, c.Arg(name, "float64", description, isRequired, false), name)
+}
+
+// StringArgs adds a variadic string type positional argument
+// to command and returns a handle reading its values.
+func (c *Command) StringArgs(name, description string, isRequired bool) *ArgRef[[]string] {
+	return newArgRef[[]string](c, c.Arg(name, "string", description, isRequired, true), name)
+}
+
+func (c *Command) getArg(name string) *Arg {
+	for _, a := range c.args {
+		if a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// argAt returns the argument filled by the word at index i, nil if there is none
+func (c *Command) argAt(i int) *Arg {
+	if i < len(c.args) {
+		return c.args[i]
+	}
+	if n := len(c.args); n > 0 && c.args[n-1].isVariadic {
+		return c.args[n-1]
+	}
+	return nil
 }
 
-// FloatFlag adds a float type value flag to command.
-func (c *command) FloatFlag(name, alias string, defaultValue float64, description string, isRequired bool) {
-	c.Flag(name, alias, "float64", defaultValue, description, isRequired)
+// bindArgs validates the positional words against the declared
+// arguments and stores them into flags under the argument names.
+// A variadic argument is stored as a quoted list of words.
+func (c *Command) bindArgs(words []string, flags Flags) error {
+	for i, a := range c.args {
+		if i >= len(words) {
+			if a.isRequired {
+				return &ArgError{Command: c.Path(), Arg: a.name, Err: ErrMissingArg}
+			}
+			return nil
+		}
+		values := words[i : i+1]
+		if a.isVariadic {
+			values = words[i:]
+		}
+		for _, v := range values {
+			if _, err := getDataTypeFunction(a.dataType)(v); err != nil {
+				return &ArgError{Command: c.Path(), Arg: a.name, Value: v, Type: a.dataType, Err: ErrInvalidValue}
+			}
+		}
+		if a.isVariadic {
+			flags[a.name] = parse.Join(values)
+			return nil
+		}
+		flags[a.name] = values[0]
+	}
+	if len(words) > len(c.args) {
+		return &ArgError{Command: c.Path(), Value: parse.Join(words[len(c.args):]), Err: ErrTooManyArgs}
+	}
+	return nil
 }
 
-// BoolFlag adds a bool type value flag to command.
-func (c *command) BoolFlag(name, alias string, description string) {
-	c.Flag(name, alias, "bool", false, description, false)
+// AllowUnknownFlags sets whether flags that are not defined on the command
+// are passed to the handler, e.g. for commands that hand them over to
+// another program. By default they are rejected.
+func (c *Command) AllowUnknownFlags(allow bool) {
+	c.allowUnknownFlags = allow
 }
 
-// StringFlag adds an String type value flag to command.
-func (c *command) StringFlag(name, alias string, defaultValue string, description string, isRequired bool) {
-	c.Flag(name, alias, "string", defaultValue, description, isRequired)
+// unknownFlags returns an error for each of the flags that
+// are not defined on the command, sorted by name.
+func (c *Command) unknownFlags(flags Flags) []error {
+	var names []string
+	for name := range flags {
+		if c.getFlag(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		errs = append(errs, &FlagError{Command: c.Path(), Flag: name, Err: ErrUnknownFlag,
+			Suggestion: suggest(name, c.flagKeys())})
+	}
+	return errs
 }
 
-func (c *command) getFlag(name string) *flag {
+// flagKeys returns the names and the aliases of the flags
+func (c *Command) flagKeys() []string {
+	var keys []string
+	for _, f := range c.flags {
+		keys = append(keys, f.name)
+		if f.alias != "" {
+			keys = append(keys, f.alias)
+		}
+	}
+	return keys
+}
+
+func (c *Command) getFlag(name string) *Flag {
 	for _, f := range c.flags {
 		if f.name == name || f.alias == name {
 			return f
@@ -81,17 +510,39 @@ func (c *command) getFlag(name string) *flag {
 	return nil
 }
 
-func (c *command) String() string {
-	n := fmt.Sprintf("usage: %s [%s flags]\n\n%s\n\nFlags: \n\n", c.name, c.name, c.description)
+// spec describes the flags of the command to the parser
+func (c *Command) spec() parse.Spec {
+	return parse.Spec{
+		Known: func(key string) bool {
+			return c.getFlag(key) != nil
+		},
+		Bool: func(key string) bool {
+			f := c.getFlag(key)
+			return f != nil && f.isBool()
+		},
+	}
+}
 
-	if _, ok := c.flags["h"]; !ok {
-		c.flags["h"] = &flag{
-			name:        "h",
-			alias:       "help",
-			dataType:    "bool",
-			description: "prints out information about the command",
-			isRequired:  false}
+func (c *Command) String() string {
+	usage := fmt.Sprintf("%s [%s flags]", c.Path(), c.name)
+	if len(c.children) > 0 {
+		usage = fmt.Sprintf("%s <command> [%s flags]", c.Path(), c.name)
+	}
+	for _, a := range c.args {
+		usage += " " + a.usage()
 	}
+	n := fmt.Sprintf("usage: %s\n\n%s\n\n", usage, c.description)
+	if len(c.aliases) > 0 {
+		n += fmt.Sprintf("Aliases: %s\n\n", strings.Join(c.aliases, ", "))
+	}
+	if len(c.args) > 0 {
+		n += "Arguments: \n\n"
+		for _, a := range c.args {
+			n += fmt.Sprint(a)
+		}
+		n += "\n"
+	}
+	n += "Flags: \n\n"
 
 	keys := make([]string, 0, len(c.flags))
 	for k := range c.flags {
@@ -104,5 +555,26 @@ func (c *command) String() string {
 		n += fmt.Sprint(f)
 	}
 
+	if len(c.constraints) > 0 {
+		n += "\n" + c.constraintsString()
+	}
+
+	if len(c.children) > 0 {
+		n += "\n" + c.childrenString()
+	}
+
 	return n
 }
+
+func (c *Command) childrenString() string {
+	buf := new(bytes.Buffer)
+	w := tabwriter.NewWriter(buf, 1, 8, 8, ' ', tabwriter.TabIndent)
+	fmt.Fprintf(w, "Commands:\n")
+	for _, child := range c.Children() {
+		fmt.Fprintf(w, "\t%s\t%s\n", child.names(), child.shortDesc)
+	}
+	fmt.Fprintf(w, "\nUse \"help %s <command>\" for more information about a command.\n", c.Path())
+	w.Flush()
+
+	return buf.String()
+}
@@ -0,0 +1,235 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// CompleteFunc returns the values of a flag or an argument that
+// begin with prefix, which are offered when Tab is pressed.
+type CompleteFunc func(prefix string) []string
+
+// CompleteValues returns a CompleteFunc offering a fixed list of values.
+func CompleteValues(values ...string) CompleteFunc {
+	return func(prefix string) []string {
+		var matches []string
+		for _, v := range values {
+			if strings.HasPrefix(v, prefix) {
+				matches = append(matches, v)
+			}
+		}
+		return matches
+	}
+}
+
+// CompleteFiles is a CompleteFunc offering the paths of files and
+// directories. Directories end with a slash so that they can be
+// completed further.
+func CompleteFiles(prefix string) []string {
+	dir, base := filepath.Split(prefix)
+	read := dir
+	if read == "" {
+		read = "."
+	}
+	infos, err := ioutil.ReadDir(read)
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, info := range infos {
+		name := info.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if info.IsDir() {
+			name += string(filepath.Separator)
+		}
+		matches = append(matches, dir+name)
+	}
+	return matches
+}
+
+// Completion sets the function completing the values of the flag or
+// the positional argument with the given name.
+func (c *Command) Completion(name string, complete CompleteFunc) error {
+	if f := c.getFlag(name); f != nil {
+		f.complete = complete
+		return nil
+	}
+	if a := c.getArg(name); a != nil {
+		a.complete = complete
+		return nil
+	}
+	return fmt.Errorf("command '%s' has no flag or argument '%s'", c.Path(), name)
+}
+
+// complete returns the sorted candidates for the word at the end of line,
+// along with the byte offset where that word begins.
+func (cli *CLI) complete(line string) ([]string, int) {
+	tokens, last, offset := parse.TokenizePartial(line)
+	return cli.completeTokens(tokens, last), offset
+}
+
+// completeTokens returns the sorted candidates for the last word
+func (cli *CLI) completeTokens(tokens []parse.Token, last parse.Token) []string {
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, c := range cli.candidates(tokens, last) {
+		if strings.HasPrefix(c, last.Value) && !seen[c] {
+			seen[c] = true
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// completeArgs serves the completion scripts, writing one candidate per
+// line for the last of the words, which is the one being completed.
+func (cli *CLI) completeArgs(words []parse.Token) error {
+	var last parse.Token
+	if n := len(words); n > 0 {
+		words, last = words[:n-1], words[n-1]
+	}
+	for _, c := range cli.completeTokens(words, last) {
+		// quitting makes no sense outside of the interactive interface
+		if c == "quit" {
+			continue
+		}
+		if _, err := fmt.Fprintln(cli.out, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// candidates walks the command tree with the complete words of the
+// line and returns what may follow them: subcommands, flags, flag
+// values or positional arguments.
+func (cli *CLI) candidates(tokens []parse.Token, last parse.Token) []string {
+	typingFlag := !last.Literal && strings.HasPrefix(last.Value, "-")
+	if len(tokens) == 0 && !typingFlag {
+		return append(commandNames(cli.commands), "help", "quit")
+	}
+
+	if len(tokens) > 0 && tokens[0].Value == "help" {
+		m := cli.commands
+		for _, t := range tokens[1:] {
+			cmd := find(m, t.Value)
+			if cmd == nil {
+				return nil
+			}
+			m = cmd.children
+		}
+		return commandNames(m)
+	}
+
+	// lines starting with a flag belong to the empty command
+	cmd := find(cli.commands, "")
+	rest := tokens
+	if len(tokens) > 0 && !tokens[0].IsFlag() {
+		cmd, rest = cli.resolveTokens(tokens)
+	}
+	if cmd == nil {
+		return nil
+	}
+
+	if typingFlag {
+		return flagNames(cmd)
+	}
+	if n := len(rest); n > 0 && rest[n-1].IsFlag() {
+		f := cmd.getFlag(flagKey(rest[n-1].Value))
+		if f == nil || f.complete == nil {
+			return nil
+		}
+		return f.complete(last.Value)
+	}
+	for _, t := range rest {
+		if t.IsFlag() {
+			return nil
+		}
+	}
+
+	var candidates []string
+	if len(rest) == 0 {
+		candidates = commandNames(cmd.children)
+	}
+	if a := cmd.argAt(len(rest)); a != nil && a.complete != nil {
+		candidates = append(candidates, a.complete(last.Value)...)
+	}
+	return candidates
+}
+
+// resolveTokens is like resolve for tokens that are not all known to be
+// words, returning the command and the tokens following its path.
+func (cli *CLI) resolveTokens(tokens []parse.Token) (*Command, []parse.Token) {
+	cmd := find(cli.commands, tokens[0].Value)
+	if cmd == nil {
+		return nil, nil
+	}
+	i := 1
+	for ; i < len(tokens) && !tokens[i].IsFlag(); i++ {
+		child := cmd.Command(tokens[i].Value)
+		if child == nil {
+			break
+		}
+		cmd = child
+	}
+	return cmd, tokens[i:]
+}
+
+// commandNames returns the names and the aliases of the commands in m
+func commandNames(m map[string]*Command) []string {
+	var names []string
+	for _, cmd := range m {
+		if cmd.name == "" {
+			continue
+		}
+		names = append(names, cmd.name)
+		names = append(names, cmd.aliases...)
+	}
+	return names
+}
+
+// flagNames returns the flags of the command as they are typed
+func flagNames(c *Command) []string {
+	var names []string
+	for _, f := range c.flags {
+		names = append(names, "-"+f.name)
+		if f.alias != "" {
+			names = append(names, "--"+f.alias)
+		}
+	}
+	return names
+}
+
+// flagKey strips the dashes of a flag
+func flagKey(s string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(s, "-"), "-")
+}
+
+// commonPrefix returns the longest prefix shared by all the strings
+func commonPrefix(ss []string) string {
+	if len(ss) == 0 {
+		return ""
+	}
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func completionCLI() *CLI {
+	c := New()
+	get := c.Simple("get", "gets", "gets")
+	get.StringFlag("f", "format", "", "", false)
+	get.BoolFlag("v", "verbose", "")
+	get.Completion("format", CompleteValues("json", "table", "text"))
+	get.StringArg("id", "", false)
+	get.Completion("id", func(prefix string) []string {
+		return []string{"id-1", "id-2", "other"}
+	})
+	list := c.Simple("list", "lists", "lists")
+	list.Alias("ls")
+	remote := c.Simple("remote", "", "")
+	remote.Simple("add", "", "")
+	remote.Simple("rm", "", "")
+	root := c.Simple("", "", "")
+	root.BoolFlag("d", "debug", "")
+	return c
+}
+
+func TestCLI_Complete(t *testing.T) {
+	c := completionCLI()
+	var tests = []struct {
+		line       string
+		candidates []string
+		offset     int
+	}{
+		{"", []string{"get", "help", "list", "ls", "quit", "remote"}, 0},
+		{"l", []string{"list", "ls"}, 0},
+		{"re", []string{"remote"}, 0},
+		{"remote ", []string{"add", "rm"}, 7},
+		{"remote a", []string{"add"}, 7},
+		{"get -", []string{"--format", "--help", "--verbose", "-f", "-h", "-v"}, 4},
+		{"get --f", []string{"--format"}, 4},
+		{"get -f ", []string{"json", "table", "text"}, 7},
+		{"get --format t", []string{"table", "text"}, 13},
+		{"get -v ", nil, 7},
+		{"get ", []string{"id-1", "id-2", "other"}, 4},
+		{"get id", []string{"id-1", "id-2"}, 4},
+		{"get id-1 ", nil, 9},
+		{"--d", []string{"--debug"}, 0},
+		{"help ", []string{"get", "list", "ls", "remote"}, 5},
+		{"help remote r", []string{"rm"}, 12},
+		{"unknown ", nil, 8},
+	}
+	for _, tt := range tests {
+		candidates, offset := c.complete(tt.line)
+		if !reflect.DeepEqual(candidates, tt.candidates) || offset != tt.offset {
+			t.Errorf("'%s': expected %v at %d, got %v at %d", tt.line, tt.candidates, tt.offset, candidates, offset)
+		}
+	}
+}
+
+func TestEditor_Complete(t *testing.T) {
+	c := completionCLI()
+	var tests = []struct {
+		description string
+		input       string
+		line        string
+		listed      string
+	}{
+		{"single command", "ge\t\r", "get ", ""},
+		{"common prefix", "get id\t\r", "get id-", ""},
+		{"list candidates", "get --format t\t\r", "get --format t", "table  text"},
+		{"flag name", "get --ver\t\r", "get --verbose ", ""},
+		{"in the middle of the line", "get  -v\x1b[D\x1b[D\x1b[Do\t\r", "get other -v", ""},
+		{"no candidates", "get id-1 \t\r", "get id-1 ", ""},
+	}
+	for _, tt := range tests {
+		h, _ := loadHistory("", historyLimit)
+		out := new(bytes.Buffer)
+		e := newEditor(strings.NewReader(tt.input), out, h)
+		e.complete = c.complete
+		line, err := e.readLine("> ")
+		if err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.description, err)
+		}
+		if line != tt.line {
+			t.Errorf("%s: expected '%s', got '%s'", tt.description, tt.line, line)
+		}
+		if tt.listed != "" && !strings.Contains(out.String(), tt.listed) {
+			t.Errorf("%s: expected candidates '%s' to be listed, got %q", tt.description, tt.listed, out.String())
+		}
+	}
+}
+
+func TestCompleteFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-icls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "docs"), 0700)
+	ioutil.WriteFile(filepath.Join(dir, "data.txt"), nil, 0600)
+	ioutil.WriteFile(filepath.Join(dir, ".hidden"), nil, 0600)
+
+	sep := string(filepath.Separator)
+	prefix := dir + sep
+	var tests = []struct {
+		prefix  string
+		matches []string
+	}{
+		{prefix, []string{prefix + "data.txt", prefix + "docs" + sep}},
+		{prefix + "do", []string{prefix + "docs" + sep}},
+		{prefix + ".", []string{prefix + ".hidden"}},
+		{prefix + "none", nil},
+	}
+	for _, tt := range tests {
+		if matches := CompleteFiles(tt.prefix); !reflect.DeepEqual(matches, tt.matches) {
+			t.Errorf("'%s': expected %v, got %v", tt.prefix, tt.matches, matches)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constraintKind is the relation a constraint sets among its flags
+type constraintKind int
+
+const (
+	exactlyOne constraintKind = iota
+	atMostOne
+	allOrNone
+	requires
+)
+
+// constraint relates flags of a command, which are checked together
+// before the handler runs. For requires the first flag needs the rest.
+type constraint struct {
+	kind  constraintKind
+	flags []string
+}
+
+// describe states the constraint, naming the flags with name
+func (c constraint) describe(name func(string) string) string {
+	names := make([]string, len(c.flags))
+	for i, f := range c.flags {
+		names[i] = name(f)
+	}
+	switch c.kind {
+	case exactlyOne:
+		return fmt.Sprintf("exactly one of %s is required", strings.Join(names, ", "))
+	case atMostOne:
+		return fmt.Sprintf("at most one of %s may be passed", strings.Join(names, ", "))
+	case allOrNone:
+		return fmt.Sprintf("%s must be passed together", strings.Join(names, ", "))
+	}
+	return fmt.Sprintf("%s requires %s", names[0], strings.Join(names[1:], ", "))
+}
+
+// check returns an error when the flags passed break the constraint
+func (c constraint) check(cmd *Command, flags Flags) error {
+	var passed, missing []string
+	for _, name := range c.flags {
+		if f := cmd.getFlag(name); f != nil && f.isSet(flags) {
+			passed = append(passed, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	err := &ConstraintError{Command: cmd.Path(), Constraint: c.describe(quote), Passed: passed}
+	switch c.kind {
+	case exactlyOne:
+		if len(passed) != 1 {
+			return err
+		}
+	case atMostOne:
+		if len(passed) > 1 {
+			return err
+		}
+	case allOrNone:
+		if len(passed) > 0 && len(missing) > 0 {
+			err.Missing = missing
+			return err
+		}
+	case requires:
+		if len(passed) > 0 && passed[0] == c.flags[0] && len(missing) > 0 {
+			err.Missing = missing
+			return err
+		}
+	}
+	return nil
+}
+
+func quote(s string) string {
+	return "'" + s + "'"
+}
+
+// isSet reports whether the flag is passed or set by its environment variable
+func (f *Flag) isSet(flags Flags) bool {
+	if _, ok := f.lookup(flags); ok {
+		return true
+	}
+	v, ok := f.envValue()
+	return ok && v != ""
+}
+
+// ExactlyOneOf requires exactly one of the flags to be passed.
+func (c *Command) ExactlyOneOf(flags ...string) error {
+	return c.addConstraint(exactlyOne, flags)
+}
+
+// AtMostOneOf allows at most one of the flags to be passed,
+// making them mutually exclusive.
+func (c *Command) AtMostOneOf(flags ...string) error {
+	return c.addConstraint(atMostOne, flags)
+}
+
+// AllOrNone requires the flags to be passed together or not at all.
+func (c *Command) AllOrNone(flags ...string) error {
+	return c.addConstraint(allOrNone, flags)
+}
+
+// Requires requires the flags in others to be passed along with flag.
+func (c *Command) Requires(flag string, others ...string) error {
+	return c.addConstraint(requires, append([]string{flag}, others...))
+}
+
+// addConstraint adds a constraint on flags, named by name or by alias
+func (c *Command) addConstraint(kind constraintKind, flags []string) error {
+	if len(flags) < 2 {
+		return fmt.Errorf("a constraint of command '%s' needs at least two flags", c.Path())
+	}
+	names := make([]string, len(flags))
+	for i, name := range flags {
+		f := c.getFlag(name)
+		if f == nil {
+			return fmt.Errorf("command '%s' has no flag '%s'", c.Path(), name)
+		}
+		names[i] = f.name
+	}
+	c.constraints = append(c.constraints, constraint{kind: kind, flags: names})
+	return nil
+}
+
+// checkConstraints returns an error for each constraint the flags break
+func (c *Command) checkConstraints(flags Flags) []error {
+	var errs []error
+	for _, con := range c.constraints {
+		if err := con.check(c, flags); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// constraintsString describes the constraints in the help
+func (c *Command) constraintsString() string {
+	n := "Constraints: \n\n"
+	for _, con := range c.constraints {
+		n += fmt.Sprintf("\t%s\n", con.describe(func(name string) string {
+			return "-" + name
+		}))
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_Constraints(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	export := c.New("export", "exports", "exports the data", func(flags cli.Flags) error {
+		return nil
+	})
+	export.BoolFlag("json", "", "json output")
+	export.BoolFlag("csv", "", "csv output")
+	export.BoolFlag("z", "gzip", "compress the output")
+	export.BoolFlag("bz", "bzip2", "compress the output")
+	export.StringFlag("u", "user", "", "the user", false)
+	export.StringFlag("p", "password", "", "the password", false)
+	export.StringFlag("key", "", "", "the TLS key", false)
+	export.StringFlag("cert", "", "", "the TLS certificate", false)
+
+	for _, err := range []error{
+		export.ExactlyOneOf("json", "csv"),
+		export.AtMostOneOf("gzip", "bz"),
+		export.AllOrNone("user", "password"),
+		export.Requires("key", "cert"),
+	} {
+		if err != nil {
+			t.Fatalf("expected no error adding a constraint, got '%v'", err)
+		}
+	}
+
+	var test = []struct {
+		line string
+		msg  string
+	}{
+		{"export --json", ""},
+		{"export --csv -z -u me -p secret --key k --cert c", ""},
+		{"export --json --cert c", ""},
+		{"export", "invalid flags of command 'export': exactly one of 'json', 'csv' is required, got none"},
+		{"export --json --csv", "invalid flags of command 'export': exactly one of 'json', 'csv' is required, got 'json', 'csv'"},
+		{"export --json -z --bzip2", "invalid flags of command 'export': at most one of 'z', 'bz' may be passed, got 'z', 'bz'"},
+		{"export --json --password secret", "invalid flags of command 'export': 'u', 'p' must be passed together, missing 'u'"},
+		{"export --json --key k", "invalid flags of command 'export': 'key' requires 'cert', missing 'cert'"},
+		{"export -z -bz --key k", "invalid flags of command 'export':" +
+			"\n\texactly one of 'json', 'csv' is required, got none" +
+			"\n\tat most one of 'z', 'bz' may be passed, got 'z', 'bz'" +
+			"\n\t'key' requires 'cert', missing 'cert'"},
+	}
+	for _, tt := range test {
+		_, err := c.Execute(tt.line)
+		if tt.msg == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+			}
+			continue
+		}
+		if !errors.Is(err, cli.ErrConstraint) || err.Error() != tt.msg {
+			t.Errorf("%s: expected error \"%s\", got \"%v\"", tt.line, tt.msg, err)
+		}
+	}
+
+	help := export.String()
+	for _, s := range []string{"Constraints:", "exactly one of -json, -csv is required", "-key requires -cert"} {
+		if !strings.Contains(help, s) {
+			t.Errorf("expected the help to contain '%s', got\n%s", s, help)
+		}
+	}
+
+	if err := export.ExactlyOneOf("json", "xml"); err == nil {
+		t.Errorf("expected an error for a constraint on an unknown flag")
+	}
+	if err := export.AtMostOneOf("json"); err == nil {
+		t.Errorf("expected an error for a constraint on a single flag")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// LevelTrace is the log level below debug set by -vvv.
+const LevelTrace = slog.LevelDebug - 4
+
+// countValue counts the times a flag is passed
+type countValue int
+
+// Set receives the values of all the occurrences of the flag. Each one
+// without a value or set to true counts once, false resets the count
+// and a number sets it, as in --verbose=2.
+func (v *countValue) Set(s string) error {
+	tokens, err := parse.Tokenize(s)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, t := range tokens {
+		switch t.Value {
+		case "", "true":
+			n++
+		case "false":
+			n = 0
+		default:
+			if n, err = strconv.Atoi(t.Value); err != nil || n < 0 {
+				return fmt.Errorf("%s is not a count", t.Value)
+			}
+		}
+	}
+	*v = countValue(n)
+	return nil
+}
+
+func (v *countValue) String() string   { return strconv.Itoa(int(*v)) }
+func (v *countValue) Type() string     { return "count" }
+func (v *countValue) Get() interface{} { return int(*v) }
+func (v *countValue) IsBoolFlag() bool { return true }
+func (v *countValue) repeatable() bool { return true }
+
+// CountFlag adds a flag counting the times it is passed, either
+// repeated as in -v -v or combined as in -vv, and returns
+// a handle reading the count.
+func (c *Command) CountFlag(name, alias, description string) *FlagRef[int] {
+	v := countValue(0)
+	return newFlagRef[int](c, c.Var(&v, name, alias, description, false), name)
+}
+
+// VerbosityLevel maps the count of a verbosity flag to a log level,
+// warn when it is not passed and then info, debug and trace.
+func VerbosityLevel(count int) slog.Level {
+	switch {
+	case count <= 0:
+		return slog.LevelWarn
+	case count == 1:
+		return slog.LevelInfo
+	case count == 2:
+		return slog.LevelDebug
+	}
+	return LevelTrace
+}
+
+// Logger returns the logger of the application, which writes to the
+// error output. Its level is warn unless the CLI is created
+// WithVerbosity and the command is passed -v.
+func (cli *CLI) Logger() *slog.Logger {
+	return cli.logger
+}
+
+// addVerbosity adds the -v, --verbose count flag to a command created
+// by a CLI WithVerbosity, and marks the command so that its subcommands
+// get the flag as well
+func (c *Command) addVerbosity() {
+	c.verbosity = true
+	c.CountFlag("v", "verbose", "increase the verbosity of the logs, e.g. -vvv")
+	c.flags["v"].verbosity = true
+}
+
+// replaceVerbosity removes the verbosity flag when the command
+// defines a flag of its own named either -v or --verbose. It fails
+// when a constraint of the command refers to the verbosity flag.
+func (c *Command) replaceVerbosity(name, alias string) error {
+	f := c.flags["v"]
+	if f == nil || !f.verbosity || (name != "v" && name != "verbose" && alias != "v" && alias != "verbose") {
+		return nil
+	}
+	for _, con := range c.constraints {
+		for _, n := range con.flags {
+			if n == "v" {
+				return fmt.Errorf("flag %s/%s replaces flag v/verbose used by a constraint of command '%s'",
+					name, alias, c.Path())
+			}
+		}
+	}
+	delete(c.flags, "v")
+	return nil
+}
+
+// setVerbosity sets the level of the logger to the
+// count of the verbosity flag passed to the command
+func (cli *CLI) setVerbosity(cmd *Command, flags Flags) {
+	if !cli.verbosity {
+		return
+	}
+	count := 0
+	if f := cmd.flags["v"]; f != nil && f.verbosity {
+		if v, err := f.typedValue(flags); err == nil {
+			count = unwrapValue(v).(int)
+		}
+	}
+	cli.level.Set(VerbosityLevel(count))
+}
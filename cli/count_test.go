@@ -0,0 +1,156 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_CountFlag(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	diag := c.Simple("diag", "diagnoses", "runs the diagnostics")
+	verbose := diag.CountFlag("v", "verbose", "the verbosity")
+	all := diag.BoolFlag("a", "all", "all checks")
+	count, checkAll := -1, false
+	diag.Handler(func(flags cli.Flags) error {
+		count, checkAll = verbose.Get(flags), all.Get(flags)
+		return nil
+	})
+
+	var test = []struct {
+		line  string
+		count int
+		all   bool
+	}{
+		{"diag", 0, false},
+		{"diag -v", 1, false},
+		{"diag -vvv", 3, false},
+		{"diag -v -v --verbose", 3, false},
+		{"diag -vav", 2, true},
+		{"diag --verbose=4", 4, false},
+		{"diag -vv --verbose=false -v", 1, false},
+	}
+	for _, tt := range test {
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+			continue
+		}
+		if count != tt.count || checkAll != tt.all {
+			t.Errorf("%s: expected count %d and all %v, got %d and %v", tt.line, tt.count, tt.all, count, checkAll)
+		}
+	}
+
+	if _, err := c.Execute("diag --verbose=many"); !errors.Is(err, cli.ErrInvalidValue) {
+		t.Errorf("expected error '%v', got '%v'", cli.ErrInvalidValue, err)
+	}
+
+	os.Setenv("DIAG_VERBOSITY", "2")
+	defer os.Unsetenv("DIAG_VERBOSITY")
+	diag.Env("v", "DIAG_VERBOSITY")
+	if _, err := c.Execute("diag"); err != nil || count != 2 {
+		t.Errorf("expected count 2 from the environment, got %d, %v", count, err)
+	}
+}
+
+func TestCLI_WithVerbosity(t *testing.T) {
+	errOut := new(bytes.Buffer)
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(errOut), cli.WithVerbosity())
+	c.NewContext("diag", "diagnoses", "runs the diagnostics", func(ctx context.Context, flags cli.Flags) error {
+		log := c.Logger()
+		log.Warn("warn")
+		log.Info("info")
+		log.Debug("debug")
+		log.Log(ctx, cli.LevelTrace, "trace")
+		return nil
+	})
+
+	var test = []struct {
+		line     string
+		messages []string
+	}{
+		{"diag", []string{"warn"}},
+		{"diag -v", []string{"warn", "info"}},
+		{"diag -vv", []string{"warn", "info", "debug"}},
+		{"diag --verbose -vv", []string{"warn", "info", "debug", "trace"}},
+		{"diag", []string{"warn"}},
+	}
+	for _, tt := range test {
+		errOut.Reset()
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+			continue
+		}
+		var messages []string
+		for _, line := range strings.Split(strings.TrimSpace(errOut.String()), "\n") {
+			messages = append(messages, line[strings.Index(line, "msg=")+4:])
+		}
+		if strings.Join(messages, " ") != strings.Join(tt.messages, " ") {
+			t.Errorf("%s: expected messages %v, got %v", tt.line, tt.messages, messages)
+		}
+	}
+
+	remote := c.Simple("remote", "remotes", "manages remotes")
+	add := remote.New("add", "adds", "adds a remote", func(flags cli.Flags) error {
+		return nil
+	})
+	own := c.New("own", "owns", "defines its own -v", func(flags cli.Flags) error {
+		return nil
+	})
+	own.BoolFlag("x", "verbose", "its own verbose flag")
+	if remote.Lookup("v") == nil || add.Lookup("verbose") == nil {
+		t.Errorf("expected the verbosity flag on the commands before they are executed")
+	}
+	if own.Lookup("v") != nil || own.Lookup("verbose").Name() != "x" {
+		t.Errorf("expected a flag of the command to replace the verbosity flag")
+	}
+
+	quiet := c.New("quiet", "quiets", "constrains -v", func(flags cli.Flags) error {
+		return nil
+	})
+	quiet.BoolFlag("q", "quiet", "no output")
+	if err := quiet.AtMostOneOf("v", "q"); err != nil {
+		t.Fatalf("expected no error adding a constraint on -v, got '%v'", err)
+	}
+	if quiet.BoolFlag("w", "verbose", "its own verbose flag").Flag() != nil {
+		t.Errorf("expected an error replacing a verbosity flag used by a constraint")
+	}
+	if _, err := c.Execute("quiet -q"); err != nil {
+		t.Errorf("expected no error, got '%v'", err)
+	}
+	if _, err := c.Execute("quiet -q -v"); !errors.Is(err, cli.ErrConstraint) {
+		t.Errorf("expected error '%v', got '%v'", cli.ErrConstraint, err)
+	}
+
+	out := new(bytes.Buffer)
+	complete := cli.New(cli.WithOutput(out), cli.WithVerbosity())
+	complete.New("diag", "diagnoses", "runs the diagnostics", nil)
+	if _, err := complete.Execute("__complete diag --"); err != nil || out.String() != "--help\n--verbose\n" {
+		t.Errorf("expected --verbose to be completed, got %q, '%v'", out.String(), err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.ExecuteArgs([]string{"remote", "add", "-v"})
+		}()
+	}
+	wg.Wait()
+
+	if cli.VerbosityLevel(0) != slog.LevelWarn || cli.VerbosityLevel(5) != cli.LevelTrace {
+		t.Errorf("expected the levels warn and trace, got %v and %v", cli.VerbosityLevel(0), cli.VerbosityLevel(5))
+	}
+}
@@ -0,0 +1,442 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// keys read by the editor. Control characters keep their
+// code while the escape sequences get negative values.
+const (
+	keyCtrlA     = 0x01
+	keyCtrlB     = 0x02
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyCtrlF     = 0x06
+	keyCtrlG     = 0x07
+	keyCtrlH     = 0x08
+	keyTab       = 0x09
+	keyLF        = 0x0a
+	keyCtrlK     = 0x0b
+	keyCtrlL     = 0x0c
+	keyEnter     = 0x0d
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlR     = 0x12
+	keyCtrlU     = 0x15
+	keyCtrlW     = 0x17
+	keyEsc       = 0x1b
+	keyBackspace = 0x7f
+
+	keyUnknown = -iota
+	keyUp
+	keyDown
+	keyRight
+	keyLeft
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyDeleteWordLeft
+	keyDeleteWordRight
+)
+
+// listWidth is the width the completion candidates are listed in
+const listWidth = 80
+
+// editor reads lines from a terminal in raw mode. It echoes the
+// input, moves the cursor, edits the line and walks the history.
+type editor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history *history
+	// complete returns the candidates for the word at the end of
+	// the line and the byte offset where the word begins
+	complete func(line string) ([]string, int)
+
+	prompt string
+	buf    []rune
+	pos    int
+}
+
+func newEditor(in io.Reader, out io.Writer, h *history) *editor {
+	return &editor{
+		in:      bufio.NewReader(in),
+		out:     out,
+		history: h,
+	}
+}
+
+// readLine shows the prompt and returns the line once enter is pressed.
+// Ctrl-C discards the line and Ctrl-D on an empty line returns io.EOF.
+func (e *editor) readLine(prompt string) (string, error) {
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	// current is the index of the history entry shown, len(entries)
+	// stands for the line being edited which is kept in saved
+	current := len(e.history.entries)
+	var saved []rune
+
+	e.refresh()
+	var pending rune
+	for {
+		k := pending
+		pending = 0
+		if k == 0 {
+			var err error
+			k, err = e.readKey()
+			if err != nil {
+				return "", err
+			}
+		}
+
+		switch k {
+		case keyEnter, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			line := string(e.buf)
+			if err := e.history.add(line); err != nil {
+				fmt.Fprintf(e.out, "failed to save history: %v\r\n", err)
+			}
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", nil
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete(e.pos, e.pos+1)
+		case keyDelete:
+			e.delete(e.pos, e.pos+1)
+		case keyBackspace, keyCtrlH:
+			e.delete(e.pos-1, e.pos)
+		case keyCtrlA, keyHome:
+			e.pos = 0
+		case keyCtrlE, keyEnd:
+			e.pos = len(e.buf)
+		case keyCtrlB, keyLeft:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyCtrlF, keyRight:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyWordLeft:
+			e.pos = e.wordStart()
+		case keyWordRight:
+			e.pos = e.wordEnd()
+		case keyCtrlW, keyDeleteWordLeft:
+			e.delete(e.wordStart(), e.pos)
+		case keyDeleteWordRight:
+			e.delete(e.pos, e.wordEnd())
+		case keyCtrlU:
+			e.delete(0, e.pos)
+		case keyCtrlK:
+			e.delete(e.pos, len(e.buf))
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP, keyUp:
+			if current == 0 {
+				break
+			}
+			if current == len(e.history.entries) {
+				saved = e.buf
+			}
+			current--
+			e.set(e.history.entries[current])
+		case keyCtrlN, keyDown:
+			if current == len(e.history.entries) {
+				break
+			}
+			current++
+			if current == len(e.history.entries) {
+				e.buf, e.pos = saved, len(saved)
+				break
+			}
+			e.set(e.history.entries[current])
+		case keyTab:
+			e.completeWord()
+		case keyCtrlR:
+			var err error
+			pending, err = e.search()
+			if err != nil {
+				return "", err
+			}
+		default:
+			if k >= 0x20 && unicode.IsPrint(k) {
+				e.insert(k)
+			}
+		}
+		e.refresh()
+	}
+}
+
+// search runs a reverse incremental search through the history,
+// filling the line with the newest entry that contains the typed
+// text. It returns the key that ended the search so that the caller
+// handles it, or 0 when the search was cancelled.
+func (e *editor) search() (rune, error) {
+	original, originalPos := e.buf, e.pos
+	var query []rune
+	match := len(e.history.entries)
+	found := true
+
+	for {
+		status := "reverse-i-search"
+		if !found {
+			status = "failed reverse-i-search"
+		}
+		fmt.Fprintf(e.out, "\r(%s)`%s': %s\x1b[K", status, string(query), string(e.buf))
+
+		k, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		from := match
+		switch {
+		case k == keyCtrlR:
+			from = match - 1
+		case k == keyBackspace || k == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+			}
+			from = len(e.history.entries) - 1
+		case k == keyCtrlG || k == keyCtrlC:
+			e.buf, e.pos = original, originalPos
+			return 0, nil
+		case k >= 0x20 && unicode.IsPrint(k):
+			query = append(query, k)
+		default:
+			e.pos = len(e.buf)
+			return k, nil
+		}
+
+		if len(query) == 0 {
+			e.buf, e.pos, found = original, originalPos, true
+			match = len(e.history.entries)
+			continue
+		}
+		i := e.history.search(string(query), from)
+		found = i >= 0
+		if found {
+			match = i
+			e.set(e.history.entries[i])
+		}
+	}
+}
+
+// completeWord completes the word before the cursor. A single candidate
+// replaces the word, while several candidates extend it to their common
+// prefix or, when there is nothing to add, are listed below the line.
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	line := string(e.buf[:e.pos])
+	candidates, offset := e.complete(line)
+	if len(candidates) == 0 {
+		return
+	}
+
+	var replacement string
+	if len(candidates) == 1 {
+		replacement = parse.Quote(candidates[0])
+		next := e.pos < len(e.buf) && unicode.IsSpace(e.buf[e.pos])
+		if !next && !strings.HasSuffix(candidates[0], string(filepath.Separator)) {
+			replacement += " "
+		}
+	} else {
+		prefix := commonPrefix(candidates)
+		if _, word, _ := parse.TokenizePartial(line); len(prefix) <= len(word.Value) {
+			e.list(candidates)
+			return
+		}
+		replacement = parse.Quote(prefix)
+	}
+
+	start := utf8.RuneCountInString(line[:offset])
+	buf := append([]rune(nil), e.buf[:start]...)
+	buf = append(buf, []rune(replacement)...)
+	e.pos = len(buf)
+	e.buf = append(buf, e.buf[len([]rune(line)):]...)
+}
+
+// list prints the candidates in columns below the line
+func (e *editor) list(candidates []string) {
+	width := 0
+	for _, c := range candidates {
+		if n := utf8.RuneCountInString(c); n > width {
+			width = n
+		}
+	}
+	width += 2
+	columns := listWidth / width
+	if columns < 1 {
+		columns = 1
+	}
+
+	fmt.Fprint(e.out, "\r\n")
+	for i, c := range candidates {
+		if i > 0 && i%columns == 0 {
+			fmt.Fprint(e.out, "\r\n")
+		}
+		fmt.Fprintf(e.out, "%-*s", width, c)
+	}
+	fmt.Fprint(e.out, "\r\n")
+}
+
+// readKey reads a single key, decoding the escape sequences of
+// the arrows and the other navigation keys.
+func (e *editor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEsc {
+		return r, err
+	}
+	// terminals send the sequences at once, so an Esc with nothing
+	// after it was pressed on its own and the next key is left alone
+	if e.in.Buffered() == 0 {
+		return keyUnknown, nil
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch r {
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case 'd':
+		return keyDeleteWordRight, nil
+	case keyBackspace:
+		return keyDeleteWordLeft, nil
+	case '[', 'O':
+	default:
+		e.in.UnreadRune()
+		return keyUnknown, nil
+	}
+
+	// control sequence: parameters followed by a final byte
+	var params []byte
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			return sequenceKey(string(params), b), nil
+		}
+		params = append(params, b)
+	}
+}
+
+func sequenceKey(params string, final byte) rune {
+	switch final {
+	case 'A':
+		return keyUp
+	case 'B':
+		return keyDown
+	case 'C':
+		if params == "1;5" || params == "1;3" {
+			return keyWordRight
+		}
+		return keyRight
+	case 'D':
+		if params == "1;5" || params == "1;3" {
+			return keyWordLeft
+		}
+		return keyLeft
+	case 'H':
+		return keyHome
+	case 'F':
+		return keyEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return keyHome
+		case "4", "8":
+			return keyEnd
+		case "3":
+			return keyDelete
+		}
+	}
+	return keyUnknown
+}
+
+// refresh redraws the prompt and the line and places the cursor
+func (e *editor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if n := len(e.buf) - e.pos; n > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", n)
+	}
+}
+
+func (e *editor) insert(r rune) {
+	buf := make([]rune, 0, len(e.buf)+1)
+	buf = append(buf, e.buf[:e.pos]...)
+	buf = append(buf, r)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos++
+}
+
+// delete removes the runes between from and to, moving the cursor to from
+func (e *editor) delete(from, to int) {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(e.buf) {
+		to = len(e.buf)
+	}
+	if from >= to {
+		return
+	}
+	buf := make([]rune, 0, len(e.buf)-(to-from))
+	buf = append(buf, e.buf[:from]...)
+	e.buf = append(buf, e.buf[to:]...)
+	e.pos = from
+}
+
+// set replaces the line and moves the cursor to its end
+func (e *editor) set(line string) {
+	e.buf = []rune(line)
+	e.pos = len(e.buf)
+}
+
+// wordStart returns the position of the beginning of the word before the cursor
+func (e *editor) wordStart() int {
+	i := e.pos
+	for i > 0 && unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the position of the end of the word after the cursor
+func (e *editor) wordEnd() int {
+	i := e.pos
+	for i < len(e.buf) && unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	for i < len(e.buf) && !unicode.IsSpace(e.buf[i]) {
+		i++
+	}
+	return i
+}
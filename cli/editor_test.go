@@ -0,0 +1,119 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestEditor_ReadLine(t *testing.T) {
+	var tests = []struct {
+		description string
+		input       string
+		line        string
+	}{
+		{"plain line", "get -f file\r", "get -f file"},
+		{"backspace", "get -ff\x7f file\r", "get -f file"},
+		{"left arrow and insert", "get file\x1b[D\x1b[D\x1b[D\x1b[D-f \r", "get -f file"},
+		{"home and end", "et\x1b[Hg\x1b[F -v\r", "get -v"},
+		{"ctrl-a and ctrl-e", "et\x01g\x05 -v\r", "get -v"},
+		{"home and end tilde", "et\x1b[1~g\x1b[4~ -v\r", "get -v"},
+		{"delete key", "gxet\x01\x1b[C\x1b[3~\r", "get"},
+		{"ctrl-w deletes word", "get -f wrong\x17right\r", "get -f right"},
+		{"ctrl-w skips spaces", "get -f wrong  \x17right\r", "get -f right"},
+		{"alt-backspace deletes word", "get wrong\x1b\x7fright\r", "get right"},
+		{"alt-d deletes next word", "get wrong right\x1bb\x1bb\x1bd\x7f\r", "get right"},
+		{"ctrl-left and ctrl-right", "a c\x1b[1;5D\x1b[1;5Db \x1b[1;5C!\r", "b a! c"},
+		{"ctrl-u and ctrl-k", "abc def\x1bb\x15\x05\x0b\r", "def"},
+		{"ctrl-c discards", "get\x03", ""},
+		{"unicode", "put ü\x7fé\r", "put é"},
+	}
+
+	for _, tt := range tests {
+		h, _ := loadHistory("", historyLimit)
+		e := newEditor(strings.NewReader(tt.input), ioutil.Discard, h)
+		line, err := e.readLine("> ")
+		if err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.description, err)
+		}
+		if line != tt.line {
+			t.Errorf("%s: expected '%s', got '%s'", tt.description, tt.line, line)
+		}
+	}
+}
+
+func TestEditor_LoneEsc(t *testing.T) {
+	var tests = []struct {
+		description string
+		input       io.Reader
+		line        string
+	}{
+		{"lone esc", io.MultiReader(strings.NewReader("get\x1b"), strings.NewReader("a\r")), "geta"},
+		{"esc and unknown key", strings.NewReader("get\x1ba\r"), "geta"},
+	}
+
+	for _, tt := range tests {
+		h, _ := loadHistory("", historyLimit)
+		e := newEditor(tt.input, ioutil.Discard, h)
+		line, err := e.readLine("> ")
+		if err != nil || line != tt.line {
+			t.Errorf("%s: expected '%s', got '%s', '%v'", tt.description, tt.line, line, err)
+		}
+	}
+}
+
+func TestEditor_ReadLineEOF(t *testing.T) {
+	h, _ := loadHistory("", historyLimit)
+	e := newEditor(strings.NewReader("ab\x1b[D\x04\x04\r\x04"), ioutil.Discard, h)
+	line, err := e.readLine("> ")
+	if err != nil || line != "a" {
+		t.Errorf("expected ctrl-d to delete under the cursor, got '%s', '%v'", line, err)
+	}
+	if _, err := e.readLine("> "); err != io.EOF {
+		t.Errorf("expected io.EOF on empty line, got '%v'", err)
+	}
+}
+
+func TestEditor_History(t *testing.T) {
+	h, _ := loadHistory("", historyLimit)
+	for _, l := range []string{"get one", "put two", "get three"} {
+		h.add(l)
+	}
+
+	var tests = []struct {
+		description string
+		input       string
+		line        string
+	}{
+		{"up recalls last", "\x1b[A\r", "get three"},
+		{"up twice", "\x1b[A\x1b[A\r", "put two"},
+		{"up stops at first", "\x1b[A\x1b[A\x1b[A\x1b[A\x1b[A\r", "get one"},
+		{"down restores edited line", "typed\x1b[A\x1b[A\x1b[B\x1b[B\r", "typed"},
+		{"ctrl-p and ctrl-n", "\x10\x10\x0e\r", "get three"},
+		{"edit recalled line", "\x1b[A\x17four\r", "get four"},
+		{"reverse search", "\x12put\r", "put two"},
+		{"reverse search older", "\x12get\x12\r", "get one"},
+		{"reverse search then edit", "\x12two\x05!\r", "put two!"},
+		{"reverse search backspace", "\x12getx\x7f\r", "get three"},
+		{"reverse search cancelled", "abc\x12put\x07\r", "abc"},
+		{"reverse search not found", "abc\x12zzz\r", "abc"},
+	}
+
+	for _, tt := range tests {
+		saved := append([]string(nil), h.entries...)
+		e := newEditor(strings.NewReader(tt.input), ioutil.Discard, h)
+		line, err := e.readLine("> ")
+		if err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.description, err)
+		}
+		if line != tt.line {
+			t.Errorf("%s: expected '%s', got '%s'", tt.description, tt.line, line)
+		}
+		h.entries = saved
+	}
+}
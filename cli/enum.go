@@ -0,0 +1,59 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// enumValue holds one of a list of allowed values
+type enumValue struct {
+	value   string
+	choices []string
+	// fold matches the choices ignoring case
+	fold bool
+}
+
+// Set accepts one of the choices and keeps it as it is spelled in the
+// choices, so that a case-insensitive flag passed JSON reads json.
+func (v *enumValue) Set(s string) error {
+	for _, c := range v.choices {
+		if c == s || (v.fold && strings.EqualFold(c, s)) {
+			v.value = c
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not one of %s", s, strings.Join(v.choices, ", "))
+}
+
+func (v *enumValue) String() string   { return v.value }
+func (v *enumValue) Type() string     { return strings.Join(v.choices, "|") }
+func (v *enumValue) Get() interface{} { return v.value }
+
+// EnumFlag adds a flag taking one of the choices, which are listed in
+// the help and completed on Tab. Any other value fails the execution
+// with the list of choices. When ignoreCase is true the values are
+// matched ignoring case and the handle reads the choice as it is
+// spelled in choices. An empty defaultValue leaves the flag empty
+// when it is not passed.
+//
+//	format := export.EnumFlag("o", "format", []string{"json", "table", "csv"}, "table", "the output format", false, false)
+func (c *Command) EnumFlag(name, alias string, choices []string, defaultValue, description string, isRequired, ignoreCase bool) *StringFlagRef {
+	v := &enumValue{choices: choices, fold: ignoreCase}
+	if len(choices) == 0 {
+		return newFlagRef[string](c, fmt.Errorf("flag %s/%s has no choices", name, alias), name)
+	}
+	if defaultValue != "" {
+		if err := v.Set(defaultValue); err != nil {
+			return newFlagRef[string](c, fmt.Errorf("flag %s/%s has an invalid default: %v", name, alias, err), name)
+		}
+	}
+	r := newFlagRef[string](c, c.Var(v, name, alias, description, isRequired), name)
+	if r.flag != nil {
+		r.flag.complete = CompleteValues(choices...)
+	}
+	return r
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_EnumFlag(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := cli.New(cli.WithOutput(out), cli.WithErrorOutput(ioutil.Discard))
+	export := c.Simple("export", "exports", "exports the data")
+	format := export.EnumFlag("o", "format", []string{"json", "table", "csv"}, "table", "the output format", false, false)
+	level := export.EnumFlag("l", "level", []string{"Low", "High"}, "", "the level", false, true)
+	var gotFormat, gotLevel string
+	export.Handler(func(flags cli.Flags) error {
+		gotFormat, gotLevel = format.Get(flags), level.Get(flags)
+		return nil
+	})
+
+	var test = []struct {
+		line   string
+		format string
+		level  string
+		err    string
+	}{
+		{"export", "table", "", ""},
+		{"export -o csv --level high", "csv", "High", ""},
+		{"export --format=json -l LOW", "json", "Low", ""},
+		{"export -o JSON", "", "", "invalid value 'JSON' for flag 'o' of command 'export', expected json|table|csv: JSON is not one of json, table, csv"},
+		{"export -l medium", "", "", "invalid value 'medium' for flag 'l' of command 'export', expected Low|High: medium is not one of Low, High"},
+	}
+	for _, tt := range test {
+		gotFormat, gotLevel = "", ""
+		_, err := c.Execute(tt.line)
+		if tt.err != "" {
+			if !errors.Is(err, cli.ErrInvalidValue) || err.Error() != tt.err {
+				t.Errorf("%s: expected error \"%s\", got \"%v\"", tt.line, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+		}
+		if gotFormat != tt.format || gotLevel != tt.level {
+			t.Errorf("%s: expected '%s' and '%s', got '%s' and '%s'", tt.line, tt.format, tt.level, gotFormat, gotLevel)
+		}
+	}
+
+	help := export.String()
+	if !strings.Contains(help, "--format <json|table|csv>") || !strings.Contains(help, "(default: table)") {
+		t.Errorf("expected the help to list the choices, got\n%s", help)
+	}
+
+	var complete = []struct {
+		line     string
+		expected string
+	}{
+		{"__complete export -o ''", "csv\njson\ntable\n"},
+		{"__complete export -o t", "table\n"},
+		{"__complete export --level H", "High\n"},
+	}
+	for _, tt := range complete {
+		out.Reset()
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("%s: expected no error, got '%v'", tt.line, err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.line, tt.expected, out.String())
+		}
+	}
+
+	if r := export.EnumFlag("x", "", []string{"a"}, "b", "", false, false); r.Flag() != nil {
+		t.Errorf("expected an invalid default to add no flag")
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The errors returned by Execute, which can be checked with errors.Is.
+var (
+	// ErrUnknownCommand is returned when the command is not defined.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrMissingFlag is returned when a required flag is not passed.
+	ErrMissingFlag = errors.New("missing required flag")
+	// ErrInvalidValue is returned when the value of a flag or
+	// an argument cannot be converted to its data type.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrUnknownFlag is returned when a flag is not defined on the command.
+	ErrUnknownFlag = errors.New("unknown flag")
+	// ErrMissingArg is returned when a required argument is not passed.
+	ErrMissingArg = errors.New("missing required argument")
+	// ErrTooManyArgs is returned when there are more words than arguments.
+	ErrTooManyArgs = errors.New("too many arguments")
+	// ErrConstraint is returned when the flags passed break a
+	// constraint of the command, such as ExactlyOneOf.
+	ErrConstraint = errors.New("flag constraint violated")
+	// ErrHandler is matched by the errors returned from the handlers.
+	ErrHandler = errors.New("handler failed")
+)
+
+// errInterrupted is returned when a command is cancelled by an interrupt signal
+var errInterrupted = errors.New("interrupted")
+
+// CommandError reports a command that could not be found.
+type CommandError struct {
+	// Command is the path of the command as it was typed
+	Command string
+	// Suggestion is the closest command path, if any
+	Suggestion string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("failed to find command '%s'%s", e.Command, didYouMean(e.Suggestion))
+}
+
+// Unwrap returns ErrUnknownCommand.
+func (e *CommandError) Unwrap() error {
+	return ErrUnknownCommand
+}
+
+// FlagError reports a flag of a command that is missing, unknown
+// or has an invalid value. Err is one of the sentinel errors.
+type FlagError struct {
+	Command string
+	Flag    string
+	Value   string
+	// Type is the data type an invalid value was expected to have
+	Type string
+	Err  error
+	// Suggestion is the closest flag of the command to an unknown flag
+	Suggestion string
+	// Reason is the error of the validator rejecting a value
+	Reason error
+}
+
+func (e *FlagError) Error() string {
+	return e.message(e.Command) + didYouMean(e.Suggestion)
+}
+
+// message describes the error, naming the command after
+// the flag unless command is empty
+func (e *FlagError) message(command string) string {
+	flag := fmt.Sprintf("flag '%s'", e.Flag)
+	if command != "" {
+		flag += fmt.Sprintf(" of command '%s'", command)
+	}
+	switch {
+	case errors.Is(e.Err, ErrMissingFlag):
+		return "missing required " + flag
+	case errors.Is(e.Err, ErrUnknownFlag):
+		return "unknown " + flag
+	case errors.Is(e.Err, ErrInvalidValue):
+		return fmt.Sprintf("invalid value '%s' for %s%s%s", e.Value, flag, expected(e.Type), reason(e.Reason))
+	}
+	return fmt.Sprintf("%s: %v", flag, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+// didYouMean formats a suggestion appended to an error
+func didYouMean(suggestion string) string {
+	if suggestion == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean '%s'?", suggestion)
+}
+
+// expected formats the data type expected for an invalid value
+func expected(dataType string) string {
+	if dataType == "" {
+		return ""
+	}
+	return fmt.Sprintf(", expected %s", dataType)
+}
+
+// reason formats the error of a validator appended to an error
+func reason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return fmt.Sprintf(": %v", err)
+}
+
+// ArgError reports a positional argument of a command that is missing
+// or has an invalid value, or the words left over when there are too
+// many arguments. Err is one of the sentinel errors.
+type ArgError struct {
+	Command string
+	Arg     string
+	Value   string
+	// Type is the data type an invalid value was expected to have
+	Type string
+	Err  error
+}
+
+func (e *ArgError) Error() string {
+	switch {
+	case errors.Is(e.Err, ErrMissingArg):
+		return fmt.Sprintf("missing required argument '%s'", e.Arg)
+	case errors.Is(e.Err, ErrTooManyArgs):
+		return fmt.Sprintf("too many arguments for command '%s': %s", e.Command, e.Value)
+	case errors.Is(e.Err, ErrInvalidValue):
+		return fmt.Sprintf("invalid value '%s' for argument '%s'%s", e.Value, e.Arg, expected(e.Type))
+	}
+	return fmt.Sprintf("argument '%s': %v", e.Arg, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ArgError) Unwrap() error {
+	return e.Err
+}
+
+// ConstraintError reports flags that break a constraint of a command.
+type ConstraintError struct {
+	Command string
+	// Constraint states the constraint that is broken
+	Constraint string
+	// Passed are the flags of the constraint that were passed
+	Passed []string
+	// Missing are the flags that should have been passed along
+	Missing []string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("invalid flags of command '%s': %s", e.Command, e.message())
+}
+
+// message describes the error without the command
+func (e *ConstraintError) message() string {
+	switch {
+	case len(e.Missing) > 0:
+		return fmt.Sprintf("%s, missing %s", e.Constraint, quoteAll(e.Missing))
+	case len(e.Passed) == 0:
+		return fmt.Sprintf("%s, got none", e.Constraint)
+	}
+	return fmt.Sprintf("%s, got %s", e.Constraint, quoteAll(e.Passed))
+}
+
+// Unwrap returns ErrConstraint.
+func (e *ConstraintError) Unwrap() error {
+	return ErrConstraint
+}
+
+// quoteAll quotes the names and joins them with commas
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// ValidationError holds every problem found with the flags of a
+// command. errors.Is and errors.As look into each of them.
+type ValidationError struct {
+	Command string
+	Errors  []error
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid flags of command '%s':", e.Command)
+	for _, err := range e.Errors {
+		if fe, ok := err.(*FlagError); ok {
+			fmt.Fprintf(&b, "\n\t%s%s", fe.message(""), didYouMean(fe.Suggestion))
+			continue
+		}
+		if ce, ok := err.(*ConstraintError); ok {
+			fmt.Fprintf(&b, "\n\t%s", ce.message())
+			continue
+		}
+		fmt.Fprintf(&b, "\n\t%v", err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors found.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// reportedError is an error that was already printed along with the
+// help of the command, so that it is not printed again.
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
+
+// HandlerError wraps the error returned by the handler of a command.
+// It matches ErrHandler and unwraps to the error of the handler.
+type HandlerError struct {
+	Command string
+	Err     error
+}
+
+func (e *HandlerError) Error() string {
+	return e.Err.Error()
+}
+
+// Is reports whether target is ErrHandler.
+func (e *HandlerError) Is(target error) bool {
+	return target == ErrHandler
+}
+
+// Unwrap returns the error of the handler.
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCoder is implemented by the errors that carry the exit status of the program.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// ExitError is an error carrying the exit status Main returns.
+type ExitError struct {
+	Code int
+	// Err is printed as the failure of the command, nothing
+	// is printed when it is nil
+	Err error
+}
+
+// Exit returns an error that makes Main return code. Handlers
+// return it to end the program with a specific status.
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// ExitCode returns the exit status.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// The exit statuses Main returns when the error carries none.
+const (
+	exitFailure     = 1
+	exitUsage       = 2
+	exitInterrupted = 130
+)
+
+// exitCode returns the exit status for the error of a command
+func exitCode(err error) int {
+	var coder ExitCoder
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &coder):
+		return coder.ExitCode()
+	case errors.Is(err, errInterrupted):
+		return exitInterrupted
+	case isUsageError(err):
+		return exitUsage
+	}
+	return exitFailure
+}
+
+// isUsageError reports whether the command was called the wrong way
+func isUsageError(err error) bool {
+	var v *ValidationError
+	if errors.As(err, &v) {
+		return true
+	}
+	for _, target := range []error{ErrUnknownCommand, ErrMissingFlag, ErrUnknownFlag,
+		ErrInvalidValue, ErrMissingArg, ErrTooManyArgs, ErrConstraint} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,22 +7,129 @@ package cli
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 )
 
-// flag holds information on specific flags
-type flag struct {
+// Flag holds information on specific flags
+type Flag struct {
 	name         string
 	alias        string
 	dataType     string
 	defaultValue interface{}
 	description  string
 	isRequired   bool
+	// env is the environment variable holding
+	// the value when the flag is not passed
+	env string
+	// custom holds the default value of a flag with
+	// a custom type, which is copied on every execution
+	custom FlagValue
+	// complete offers the values of the flag on Tab
+	complete CompleteFunc
+	// validators check the value of the flag when it is set
+	validators []func(flags Flags) error
+	// verbosity marks the -v, --verbose flag added WithVerbosity
+	verbosity bool
 }
 
-func (f *flag) defaultValueToString() string {
+// Name returns the name of the flag, used as -name.
+func (f *Flag) Name() string {
+	return f.name
+}
+
+// Alias returns the long name of the flag, used as --alias.
+func (f *Flag) Alias() string {
+	return f.alias
+}
+
+// Type returns the data type of the flag value.
+func (f *Flag) Type() string {
+	return f.dataType
+}
+
+// DefaultValue returns the value used when the flag is not passed.
+func (f *Flag) DefaultValue() interface{} {
+	return f.defaultValue
+}
+
+// Description returns the description of the flag.
+func (f *Flag) Description() string {
+	return f.description
+}
+
+// IsRequired reports whether the flag must be passed.
+func (f *Flag) IsRequired() bool {
+	return f.isRequired
+}
+
+// Env returns the environment variable read when the flag is not passed.
+func (f *Flag) Env() string {
+	return f.env
+}
+
+// envValue returns the value of the environment variable of the flag
+func (f *Flag) envValue() (string, bool) {
+	if f.env == "" {
+		return "", false
+	}
+	return os.LookupEnv(f.env)
+}
+
+// lookup returns the value of the flag passed by its name or its alias
+func (f *Flag) lookup(flags Flags) (string, bool) {
+	if v, ok := flags[f.name]; ok {
+		return v, true
+	}
+	if f.alias == "" {
+		return "", false
+	}
+	v, ok := flags[f.alias]
+	return v, ok
+}
+
+// value returns the value of the flag passed to the command, read
+// from its environment variable or its default value, in this order.
+func (f *Flag) value(flags Flags) string {
+	if s, ok := f.lookup(flags); ok {
+		if s == "" && f.isBool() {
+			return "true"
+		}
+		return s
+	}
+	if s, ok := f.envValue(); ok {
+		return s
+	}
+	return f.exactDefault()
+}
+
+// exactDefault formats the default value so that converting it back
+// gives the same value, unlike the %f of the help.
+func (f *Flag) exactDefault() string {
+	if f.custom == nil {
+		switch v := f.defaultValue.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		case float32:
+			return strconv.FormatFloat(float64(v), 'g', -1, 32)
+		case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return fmt.Sprint(v)
+		}
+	}
+	return f.defaultValueToString()
+}
+
+func (f *Flag) defaultValueToString() string {
+	if f.custom != nil {
+		return f.custom.String()
+	}
 	value := f.defaultValue
+	if value == nil {
+		return ""
+	}
 	valueType := reflect.TypeOf(value).String()
 	switch valueType {
 	case "string":
@@ -40,7 +147,7 @@ func (f *flag) defaultValueToString() string {
 	}
 }
 
-func (f *flag) String() string {
+func (f *Flag) String() string {
 	buf := new(bytes.Buffer)
 	w := tabwriter.NewWriter(buf, 1, 8, 4, ' ', tabwriter.TabIndent)
 
@@ -51,8 +158,21 @@ func (f *flag) String() string {
 	if f.alias != "" {
 		alias = fmt.Sprintf("--%s", f.alias)
 	}
+	if f.custom != nil && !f.isBool() {
+		// custom types show what they expect and their default
+		alias = strings.TrimSpace(fmt.Sprintf("%s <%s>", alias, f.dataType))
+		if ft, ok := f.custom.(formatter); ok {
+			req = fmt.Sprintf("(format: %s)", ft.Format())
+		}
+		if d := f.custom.String(); d != "" {
+			req = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", req, d))
+		}
+	}
 	if f.isRequired {
-		req = fmt.Sprintf("(required: %v)", f.isRequired)
+		req = strings.TrimSpace(fmt.Sprintf("%s (required: %v)", req, f.isRequired))
+	}
+	if f.env != "" {
+		req = strings.TrimSpace(fmt.Sprintf("%s (env: %s)", req, f.env))
 	}
 	fmt.Fprintf(w, "\t%s\t%s\n\t\t\t\t%s %s\n", name, alias, f.description, req)
 	w.Flush()
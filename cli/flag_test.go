@@ -44,13 +44,15 @@ func TestDefaultValueToString(t *testing.T) {
 
 func TestBooleanFlags(t *testing.T) {
 	c := initializeCommand()
-	_, flags := c.parse("get -b")
+	r, _ := c.parse("get -b")
+	flags := Flags(r.Flags)
 	b, err := BoolValue("b", "bool", flags)
 	if !b && err != nil {
 		t.Errorf("Expecting boolean flag to exist, instead got: %v", err)
 	}
 
-	_, flags = c.parse("get")
+	r, _ = c.parse("get")
+	flags = Flags(r.Flags)
 	b, err = BoolValue("b", "bool", flags)
 	if err == nil || b {
 		t.Errorf("Expecting boolean flag to not exist, instead got: %v", b)
@@ -0,0 +1,140 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FlagValue is the interface to the value of a flag with a custom type,
+// modeled on flag.Value of the standard library. Set parses the value
+// passed to the command, String renders the value and Type names the
+// type in the help, e.g. "level" or "version".
+//
+// Every execution works on a new copy of the value given to Var, so
+// FlagValue should be implemented by a pointer to a type that can be
+// copied. A FlagValue with an IsBoolFlag() bool method returning true
+// takes no value and is set to "true" when passed, like a bool flag.
+type FlagValue interface {
+	String() string
+	Set(string) error
+	Type() string
+}
+
+// boolFlag is implemented by the values of flags that take no value
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// Var adds a flag with a custom type to the command. The value holds
+// the default value of the flag and its copies receive the values
+// passed to the command. The handler finds the value set in Values
+// or with cli.Value.
+//
+//	level := logLevel(info)
+//	cmd.Var(&level, "l", "level", "the log level", false)
+func (c *Command) Var(value FlagValue, name, alias, description string, isRequired bool) error {
+	if value == nil {
+		return fmt.Errorf("flag %s/%s has no value", name, alias)
+	}
+	if c.getArg(name) != nil || c.getArg(alias) != nil {
+		return fmt.Errorf("flag %s/%s clashes with a positional argument", name, alias)
+	}
+	if err := c.replaceVerbosity(name, alias); err != nil {
+		return err
+	}
+	c.flags[name] = &Flag{
+		name:         name,
+		alias:        alias,
+		dataType:     value.Type(),
+		defaultValue: value,
+		description:  description,
+		isRequired:   isRequired,
+		custom:       value,
+	}
+	return nil
+}
+
+// cloneValue returns a copy of v, so that setting it leaves v unchanged
+func cloneValue(v FlagValue) FlagValue {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return v
+	}
+	c := reflect.New(rv.Elem().Type())
+	c.Elem().Set(rv.Elem())
+	return c.Interface().(FlagValue)
+}
+
+// isBool reports whether the flag takes no value
+func (f *Flag) isBool() bool {
+	if b, ok := f.custom.(boolFlag); ok {
+		return b.IsBoolFlag()
+	}
+	return f.dataType == "bool"
+}
+
+// parse converts s to the data type of the flag. A flag with a
+// custom type gets a new copy of its value set to s.
+func (f *Flag) parse(s string) (interface{}, error) {
+	if f.custom == nil {
+		return getDataTypeFunction(f.dataType)(s)
+	}
+	v := cloneValue(f.custom)
+	if err := v.Set(s); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// typedValue returns the value of the flag converted to its data type,
+// which is the value passed, the value of its environment variable or
+// its default value, in this order.
+func (f *Flag) typedValue(flags Flags) (interface{}, error) {
+	s, passed := f.lookup(flags)
+	switch {
+	case passed && s == "" && f.isBool():
+		// bool flags are set by being passed
+		s = "true"
+	case passed && s != "":
+	default:
+		env, ok := f.envValue()
+		if ok && env != "" {
+			s = f.occurrence(env)
+			break
+		}
+		return f.typedDefault(), nil
+	}
+	return f.parse(s)
+}
+
+// typedDefault returns the default value as parse would convert it,
+// without formatting it as a string that could lose precision.
+// A nil default is the zero value of the data type.
+func (f *Flag) typedDefault() interface{} {
+	if f.custom != nil {
+		return cloneValue(f.custom)
+	}
+	switch v := f.defaultValue.(type) {
+	case nil:
+		switch f.dataType {
+		case "int":
+			return int64(0)
+		case "float64":
+			return float64(0)
+		case "bool":
+			return false
+		}
+		if convert := getDataTypeFunction(f.dataType); convert != nil && f.dataType != "string" {
+			v, _ := convert("0")
+			return v
+		}
+		return ""
+	case int:
+		return int64(v)
+	}
+	return f.defaultValue
+}
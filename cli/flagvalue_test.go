@@ -0,0 +1,103 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+type logLevel int
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l *logLevel) String() string {
+	return levelNames[*l]
+}
+
+func (l *logLevel) Set(s string) error {
+	for i, n := range levelNames {
+		if n == s {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %s", s)
+}
+
+func (l *logLevel) Type() string {
+	return "level"
+}
+
+// switchValue is a custom flag that takes no value
+type switchValue bool
+
+func (s *switchValue) String() string     { return fmt.Sprint(bool(*s)) }
+func (s *switchValue) Set(v string) error { *s = v == "true"; return nil }
+func (s *switchValue) Type() string       { return "switch" }
+func (s *switchValue) IsBoolFlag() bool   { return true }
+
+func TestCommand_Var(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	var level *logLevel
+	var on *switchValue
+	run := c.NewValues("run", "runs", "runs the job", func(ctx context.Context, v cli.Values) error {
+		level = v.Get("level").(*logLevel)
+		on = v.Get("s").(*switchValue)
+		return nil
+	})
+	def := logLevel(1)
+	if err := run.Var(&def, "l", "level", "the log level", false); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	var sw switchValue
+	run.Var(&sw, "s", "", "a switch", false)
+
+	var test = []struct {
+		line  string
+		level string
+		on    bool
+	}{
+		{"run -l debug -s", "debug", true},
+		{"run", "info", false},
+		{"run --level error", "error", false},
+	}
+	for _, tt := range test {
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Fatalf("%s: expected no error, got '%v'", tt.line, err)
+		}
+		if level.String() != tt.level || bool(*on) != tt.on {
+			t.Errorf("%s: expected %s and %v, got %s and %v", tt.line, tt.level, tt.on, level, *on)
+		}
+	}
+	if def != 1 {
+		t.Errorf("expected the default value to be left unchanged, got %s", def.String())
+	}
+
+	_, err := c.Execute("run -l loud")
+	var flagErr *cli.FlagError
+	if !errors.As(err, &flagErr) || flagErr.Type != "level" || flagErr.Value != "loud" {
+		t.Errorf("expected invalid level error, got '%v'", err)
+	}
+
+	l, err := cli.Value[*logLevel](run, "l", cli.Flags{"l": "warn"})
+	if err != nil || l.String() != "warn" {
+		t.Errorf("expected warn, got %v, '%v'", l, err)
+	}
+	if _, err := cli.Value[int](run, "l", cli.Flags{}); err == nil {
+		t.Errorf("expected an error for the wrong type")
+	}
+
+	help := run.Lookup("l").String()
+	if !strings.Contains(help, "--level <level>") || !strings.Contains(help, "(default: info)") {
+		t.Errorf("expected the type and the default in the help, got:\n%s", help)
+	}
+}
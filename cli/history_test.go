@@ -0,0 +1,70 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistory_Persist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-icls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "history")
+
+	h, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("expected no error for missing file, got '%v'", err)
+	}
+	for _, l := range []string{"one", "", "two", "two", "  three  "} {
+		if err := h.add(l); err != nil {
+			t.Fatalf("expected no error, got '%v'", err)
+		}
+	}
+	expected := []string{"one", "two", "three"}
+	if !reflect.DeepEqual(h.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, h.entries)
+	}
+
+	h.add("four")
+	h, err = loadHistory(path, 3)
+	if err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	expected = []string{"two", "three", "four"}
+	if !reflect.DeepEqual(h.entries, expected) {
+		t.Errorf("expected %v, got %v", expected, h.entries)
+	}
+	b, _ := ioutil.ReadFile(path)
+	if strings.Count(string(b), "\n") != 3 {
+		t.Errorf("expected the file to be trimmed to the limit, got:\n%s", b)
+	}
+}
+
+func TestHistory_Search(t *testing.T) {
+	h := &history{entries: []string{"get a", "put b", "get c"}, limit: historyLimit}
+	var tests = []struct {
+		query string
+		from  int
+		index int
+	}{
+		{"get", 10, 2},
+		{"get", 1, 0},
+		{"put", 2, 1},
+		{"del", 2, -1},
+	}
+	for _, tt := range tests {
+		if i := h.search(tt.query, tt.from); i != tt.index {
+			t.Errorf("search '%s' from %d: expected %d, got %d", tt.query, tt.from, tt.index, i)
+		}
+	}
+}
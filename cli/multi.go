@@ -0,0 +1,178 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// repeatable is implemented by the values of flags that may be passed
+// several times. Set receives all the values passed, quoted and
+// joined with spaces like the values of a variadic argument.
+type repeatable interface {
+	repeatable() bool
+}
+
+// splitValues returns the values passed to a repeated flag,
+// splitting each of them at the commas.
+func splitValues(s string) ([]string, error) {
+	tokens, err := parse.Tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, t := range tokens {
+		for _, v := range strings.Split(t.Value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// stringSliceValue holds the values of a repeated string flag
+type stringSliceValue []string
+
+func (v *stringSliceValue) Set(s string) error {
+	values, err := splitValues(s)
+	if err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+func (v *stringSliceValue) String() string   { return strings.Join(*v, ",") }
+func (v *stringSliceValue) Type() string     { return "strings" }
+func (v *stringSliceValue) Get() interface{} { return []string(*v) }
+func (v *stringSliceValue) Format() string   { return "repeated or comma-separated" }
+func (v *stringSliceValue) repeatable() bool { return true }
+
+// intSliceValue holds the values of a repeated int flag
+type intSliceValue []int
+
+func (v *intSliceValue) Set(s string) error {
+	values, err := splitValues(s)
+	if err != nil {
+		return err
+	}
+	ints := make([]int, len(values))
+	for i, value := range values {
+		if ints[i], err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s is not an int", value)
+		}
+	}
+	*v = ints
+	return nil
+}
+
+func (v *intSliceValue) String() string {
+	s := make([]string, len(*v))
+	for i, n := range *v {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+func (v *intSliceValue) Type() string     { return "ints" }
+func (v *intSliceValue) Get() interface{} { return []int(*v) }
+func (v *intSliceValue) Format() string   { return "repeated or comma-separated" }
+func (v *intSliceValue) repeatable() bool { return true }
+
+// stringMapValue holds the key=value pairs of a repeated flag
+type stringMapValue map[string]string
+
+func (v *stringMapValue) Set(s string) error {
+	values, err := splitValues(s)
+	if err != nil {
+		return err
+	}
+	m := make(map[string]string, len(values))
+	for _, value := range values {
+		i := strings.Index(value, "=")
+		if i <= 0 {
+			return fmt.Errorf("%s is not a key=value pair", value)
+		}
+		m[value[:i]] = value[i+1:]
+	}
+	*v = m
+	return nil
+}
+
+func (v *stringMapValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for k, value := range *v {
+		pairs = append(pairs, k+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *stringMapValue) Type() string     { return "key=value" }
+func (v *stringMapValue) Get() interface{} { return map[string]string(*v) }
+func (v *stringMapValue) Format() string   { return "repeated or comma-separated" }
+func (v *stringMapValue) repeatable() bool { return true }
+
+// StringSliceFlag adds a flag that may be repeated, -t a -t b, or take
+// comma-separated values, -t a,b. The handle returns all the values.
+func (c *Command) StringSliceFlag(name, alias string, defaultValue []string, description string, isRequired bool) *FlagRef[[]string] {
+	v := stringSliceValue(defaultValue)
+	return newFlagRef[[]string](c, c.Var(&v, name, alias, description, isRequired), name)
+}
+
+// IntSliceFlag adds an int flag that may be repeated or take
+// comma-separated values, like StringSliceFlag.
+func (c *Command) IntSliceFlag(name, alias string, defaultValue []int, description string, isRequired bool) *FlagRef[[]int] {
+	v := intSliceValue(defaultValue)
+	return newFlagRef[[]int](c, c.Var(&v, name, alias, description, isRequired), name)
+}
+
+// StringMapFlag adds a flag taking key=value pairs, repeated as in
+// -D a=1 -D b=2 or comma-separated as in -D a=1,b=2.
+func (c *Command) StringMapFlag(name, alias string, defaultValue map[string]string, description string, isRequired bool) *FlagRef[map[string]string] {
+	v := stringMapValue(defaultValue)
+	return newFlagRef[map[string]string](c, c.Var(&v, name, alias, description, isRequired), name)
+}
+
+// isRepeatable reports whether the flag keeps all the values passed
+func (f *Flag) isRepeatable() bool {
+	r, ok := f.custom.(repeatable)
+	return ok && r.repeatable()
+}
+
+// occurrence prepares a value read from the environment for Set, which
+// tokenizes the values of a repeatable flag joined by collectRepeated,
+// so that the value is taken as a single occurrence.
+func (f *Flag) occurrence(s string) string {
+	if f.isRepeatable() {
+		return parse.Quote(s)
+	}
+	return s
+}
+
+// collectRepeated stores in flags all the values of the repeatable flags,
+// in the order they were passed by name or by alias, under the flag name.
+func (c *Command) collectRepeated(occurrences []parse.Flag, flags Flags) {
+	values := make(map[*Flag][]string)
+	for _, o := range occurrences {
+		f := c.getFlag(o.Key)
+		if f == nil || !f.isRepeatable() {
+			continue
+		}
+		values[f] = append(values[f], o.Value)
+	}
+	for f, v := range values {
+		if f.alias != "" {
+			delete(flags, f.alias)
+		}
+		flags[f.name] = parse.Join(v)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_MultiValueFlags(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	build := c.Simple("build", "builds", "builds the project")
+	tags := build.StringSliceFlag("t", "tag", []string{"latest"}, "the image tags", false)
+	ports := build.IntSliceFlag("p", "port", nil, "the exposed ports", false)
+	defs := build.StringMapFlag("D", "define", nil, "the build variables", false)
+
+	var passed cli.Flags
+	build.Handler(func(flags cli.Flags) error {
+		passed = flags
+		return nil
+	})
+
+	var test = []struct {
+		line  string
+		tags  []string
+		ports []int
+		defs  map[string]string
+	}{
+		{"build -t a --tag 'b c' -t d,e", []string{"a", "b c", "d", "e"}, nil, map[string]string{}},
+		{"build -p 80 -p 443,8080", []string{"latest"}, []int{80, 443, 8080}, map[string]string{}},
+		{"build -D os=linux -D arch=arm64,cgo=0 --define x=a=b", []string{"latest"}, nil,
+			map[string]string{"os": "linux", "arch": "arm64", "cgo": "0", "x": "a=b"}},
+	}
+	for _, tt := range test {
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Fatalf("%s: expected no error, got '%v'", tt.line, err)
+		}
+		if got := tags.Get(passed); !reflect.DeepEqual(got, tt.tags) {
+			t.Errorf("%s: expected tags %v, got %v", tt.line, tt.tags, got)
+		}
+		if got := ports.Get(passed); !reflect.DeepEqual(got, tt.ports) {
+			t.Errorf("%s: expected ports %v, got %v", tt.line, tt.ports, got)
+		}
+		if got := defs.Get(passed); len(got) != len(tt.defs) || (len(got) > 0 && !reflect.DeepEqual(got, tt.defs)) {
+			t.Errorf("%s: expected definitions %v, got %v", tt.line, tt.defs, got)
+		}
+	}
+
+	for _, line := range []string{"build -p 80,http", "build -D novalue"} {
+		if _, err := c.Execute(line); !errors.Is(err, cli.ErrInvalidValue) {
+			t.Errorf("%s: expected invalid value error, got '%v'", line, err)
+		}
+	}
+
+	os.Setenv("GO_ICLS_TEST_TAGS", "O'Brien, b")
+	defer os.Unsetenv("GO_ICLS_TEST_TAGS")
+	build.Env("t", "GO_ICLS_TEST_TAGS")
+	if _, err := c.Execute("build"); err != nil {
+		t.Fatalf("expected no error for the tags in the environment, got '%v'", err)
+	}
+	if got := tags.Get(passed); !reflect.DeepEqual(got, []string{"O'Brien", "b"}) {
+		t.Errorf("expected the tags of the environment, got %v", got)
+	}
+
+	if help := build.String(); !strings.Contains(help, "--tag <strings>") || !strings.Contains(help, "(default: latest)") {
+		t.Errorf("expected the type and the default of the slice flag in the help, got:\n%s", help)
+	}
+}
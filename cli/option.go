@@ -0,0 +1,133 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Option configures a CLI created with New.
+type Option func(cli *CLI)
+
+// WithInput sets the reader the interactive interface reads commands from.
+func WithInput(r io.Reader) Option {
+	return func(cli *CLI) {
+		cli.in = r
+	}
+}
+
+// WithOutput sets the writer for the prompt, the help and the command output.
+func WithOutput(w io.Writer) Option {
+	return func(cli *CLI) {
+		cli.out = w
+	}
+}
+
+// WithErrorOutput sets the writer for the errors of the executed commands.
+func WithErrorOutput(w io.Writer) Option {
+	return func(cli *CLI) {
+		cli.err = w
+	}
+}
+
+// WithName sets the program name shown in the usage.
+func WithName(name string) Option {
+	return func(cli *CLI) {
+		cli.name = name
+	}
+}
+
+// WithArgs sets the arguments Main executes instead of os.Args[1:].
+// With no arguments Main starts the interactive interface.
+func WithArgs(args []string) Option {
+	return func(cli *CLI) {
+		cli.args = args
+	}
+}
+
+// WithVerbosity adds the count flag -v, --verbose to every command
+// created by this CLI that does not define either name itself. Each
+// -v lowers the level of the Logger from warn to info, debug and
+// trace, as in -vvv.
+func WithVerbosity() Option {
+	return func(cli *CLI) {
+		cli.verbosity = true
+	}
+}
+
+// WithHistoryFile sets the file where the lines entered in the terminal
+// are saved, so that they can be recalled in the next sessions.
+func WithHistoryFile(path string) Option {
+	return func(cli *CLI) {
+		cli.historyFile = path
+	}
+}
+
+// Input returns the reader the CLI reads commands from.
+func (cli *CLI) Input() io.Reader {
+	return cli.in
+}
+
+// Output returns the writer handlers should use for their output.
+func (cli *CLI) Output() io.Writer {
+	return cli.out
+}
+
+// ErrorOutput returns the writer handlers should use for their errors.
+func (cli *CLI) ErrorOutput() io.Writer {
+	return cli.err
+}
+
+// Name returns the program name shown in the usage.
+func (cli *CLI) Name() string {
+	return cli.name
+}
+
+// contextKey keys the writers of the CLI in the handler context
+type contextKey int
+
+const (
+	inputKey contextKey = iota
+	outputKey
+	errorOutputKey
+)
+
+// withIO returns a copy of ctx carrying the reader and the writers
+// of the CLI, which the handlers get with InputFrom, OutputFrom
+// and ErrorOutputFrom.
+func (cli *CLI) withIO(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, inputKey, cli.in)
+	ctx = context.WithValue(ctx, outputKey, cli.out)
+	return context.WithValue(ctx, errorOutputKey, cli.err)
+}
+
+// InputFrom returns the reader of the CLI executing the handler that
+// got ctx, or os.Stdin when ctx does not come from a CLI.
+func InputFrom(ctx context.Context) io.Reader {
+	if r, ok := ctx.Value(inputKey).(io.Reader); ok {
+		return r
+	}
+	return os.Stdin
+}
+
+// OutputFrom returns the writer for the output of the handler that
+// got ctx, or os.Stdout when ctx does not come from a CLI.
+func OutputFrom(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(outputKey).(io.Writer); ok {
+		return w
+	}
+	return os.Stdout
+}
+
+// ErrorOutputFrom returns the writer for the errors of the handler
+// that got ctx, or os.Stderr when ctx does not come from a CLI.
+func ErrorOutputFrom(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(errorOutputKey).(io.Writer); ok {
+		return w
+	}
+	return os.Stderr
+}
@@ -0,0 +1,129 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCLI_RunWithStreams(t *testing.T) {
+	in := strings.NewReader("say hello\nbogus\n-h\nquit\nsay never\n")
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	c := cli.New(cli.WithInput(in), cli.WithOutput(out), cli.WithErrorOutput(errOut), cli.WithName("app"))
+	say := c.New("say", "says", "says a word", func(flags cli.Flags) error {
+		fmt.Fprintf(c.Output(), "said %s\n", c.ArgValue("word", "say", flags))
+		return nil
+	})
+	say.StringArg("word", "the word", true)
+	c.Run()
+
+	for _, s := range []string{"> said hello\n", "Usage:\n\n\tapp <command> [options]", "\tsay", "Use \"app <command> -h\""} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain '%s', got:\n%s", s, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "never") {
+		t.Errorf("expected no commands to run after quit, got:\n%s", out.String())
+	}
+	if errOut.String() != "command failed: failed to find command 'bogus'\n" {
+		t.Errorf("unexpected error output: %q", errOut.String())
+	}
+	if c.Name() != "app" || c.Input() != in {
+		t.Errorf("expected name and input to be set by the options")
+	}
+}
+
+func TestCLI_RunStopsAtEndOfInput(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := cli.New(cli.WithInput(strings.NewReader("-h\n")), cli.WithOutput(out))
+	c.Run()
+	if !strings.HasSuffix(out.String(), "> ") {
+		t.Errorf("expected output to end with the prompt, got:\n%s", out.String())
+	}
+}
+
+func TestCLI_Main(t *testing.T) {
+	var test = []struct {
+		args   []string
+		status int
+		out    string
+		errOut string
+	}{
+		{[]string{"say", "hello world"}, 0, "said [hello world]\n", ""},
+		{[]string{"say", "it's", "-u"}, 0, "said [IT'S]\n", ""},
+		{[]string{"say", "fail"}, 3, "", "command failed: failed on purpose\n"},
+		{[]string{"say", "quiet"}, 4, "", ""},
+		{[]string{"bogus"}, 2, "", "command failed: failed to find command 'bogus'\n"},
+		{nil, 0, "> said [hi]\n> ", ""},
+	}
+
+	for _, tt := range test {
+		out := new(bytes.Buffer)
+		errOut := new(bytes.Buffer)
+		c := cli.New(cli.WithArgs(tt.args), cli.WithInput(strings.NewReader("say hi\n")),
+			cli.WithOutput(out), cli.WithErrorOutput(errOut))
+		say := c.New("say", "says", "says a word", func(flags cli.Flags) error {
+			word := c.ArgValue("word", "say", flags)
+			switch word {
+			case "fail":
+				return cli.Exit(3, errors.New("failed on purpose"))
+			case "quiet":
+				return cli.Exit(4, nil)
+			}
+			if _, ok := flags["u"]; ok {
+				word = strings.ToUpper(word)
+			}
+			fmt.Fprintf(c.Output(), "said [%s]\n", word)
+			return nil
+		})
+		say.StringArg("word", "the word", true)
+		say.BoolFlag("u", "upper", "upper case")
+
+		if status := c.Main(); status != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.args, tt.status, status)
+		}
+		if out.String() != tt.out {
+			t.Errorf("%v: expected output %q, got %q", tt.args, tt.out, out.String())
+		}
+		if errOut.String() != tt.errOut {
+			t.Errorf("%v: expected error output %q, got %q", tt.args, tt.errOut, errOut.String())
+		}
+	}
+}
+
+func TestOutputFrom(t *testing.T) {
+	in := strings.NewReader("")
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	c := cli.New(cli.WithInput(in), cli.WithOutput(out), cli.WithErrorOutput(errOut))
+	remote := c.Simple("remote", "remotes", "manages remotes")
+	var input io.Reader
+	remote.NewContext("add", "adds", "adds a remote", func(ctx context.Context, flags cli.Flags) error {
+		input = cli.InputFrom(ctx)
+		fmt.Fprint(cli.OutputFrom(ctx), "added")
+		fmt.Fprint(cli.ErrorOutputFrom(ctx), "warned")
+		return nil
+	})
+
+	if _, err := c.Execute("remote add"); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	if input != in || out.String() != "added" || errOut.String() != "warned" {
+		t.Errorf("expected the handler to get the streams of the CLI, got %q and %q", out.String(), errOut.String())
+	}
+	if cli.OutputFrom(context.Background()) != os.Stdout || cli.ErrorOutputFrom(context.Background()) != os.Stderr {
+		t.Errorf("expected the standard streams for a context that does not come from a CLI")
+	}
+}
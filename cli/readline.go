@@ -0,0 +1,88 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// lineReader reads the commands of the interactive interface line by line
+type lineReader interface {
+	readLine(prompt string) (string, error)
+	close()
+}
+
+// newLineReader returns a line editor when the input is a terminal
+// and falls back to scanning the input line by line otherwise.
+func (cli *CLI) newLineReader() lineReader {
+	f, ok := cli.in.(*os.File)
+	if !ok || !isTerminal(int(f.Fd())) {
+		return &scanReader{scanner: bufio.NewScanner(cli.in), out: cli.out}
+	}
+
+	h, err := loadHistory(cli.historyFile, historyLimit)
+	if err != nil {
+		fmt.Fprintf(cli.err, "failed to load history: %v\n", err)
+	}
+	e := newEditor(f, cli.out, h)
+	e.complete = cli.complete
+	return &termReader{fd: int(f.Fd()), editor: e}
+}
+
+// scanReader reads lines from an input that is not a terminal
+type scanReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scanReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *scanReader) close() {}
+
+// termReader reads lines from a terminal through the editor, keeping
+// the terminal in raw mode only while a line is being read.
+type termReader struct {
+	fd     int
+	editor *editor
+
+	mu      sync.Mutex
+	restore func() error
+}
+
+func (r *termReader) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	r.restore = restore
+	r.mu.Unlock()
+	defer r.close()
+
+	return r.editor.readLine(prompt)
+}
+
+// close restores the terminal if it is still in raw mode
+func (r *termReader) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.restore != nil {
+		r.restore()
+		r.restore = nil
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// errUnsupportedType is returned when a value cannot be converted to a Go type
+var errUnsupportedType = errors.New("unsupported type")
+
+// FlagRef is a handle to a flag returned when the flag is added to a
+// command. It reads the value of the flag without naming the flag or
+// the command again.
+type FlagRef[T any] struct {
+	cmd  *Command
+	flag *Flag
+}
+
+// The handles returned by the flag functions of Command.
+type (
+	IntFlagRef    = FlagRef[int]
+	FloatFlagRef  = FlagRef[float64]
+	BoolFlagRef   = FlagRef[bool]
+	StringFlagRef = FlagRef[string]
+)
+
+func newFlagRef[T any](c *Command, err error, name string) *FlagRef[T] {
+	r := &FlagRef[T]{cmd: c}
+	if err == nil {
+		r.flag = c.getFlag(name)
+	}
+	return r
+}
+
+// Flag returns the flag, nil if it could not be added to the command.
+func (r *FlagRef[T]) Flag() *Flag {
+	return r.flag
+}
+
+// Get returns the value of the flag in the flags passed to the handler,
+// or its default value when it is not passed. The values are checked
+// before the handler runs, so the zero value is returned only when the
+// flag could not be added.
+func (r *FlagRef[T]) Get(flags Flags) T {
+	var v T
+	if r.flag == nil {
+		return v
+	}
+	if r.flag.custom != nil {
+		x, _ := r.flag.typedValue(flags)
+		v, _ = customValue[T](x)
+		return v
+	}
+	v, _ = convert[T](r.flag.value(flags))
+	return v
+}
+
+// ArgRef is a handle to a positional argument returned when the
+// argument is added to a command.
+type ArgRef[T any] struct {
+	cmd *Command
+	arg *Arg
+}
+
+func newArgRef[T any](c *Command, err error, name string) *ArgRef[T] {
+	r := &ArgRef[T]{cmd: c}
+	if err == nil {
+		r.arg = c.getArg(name)
+	}
+	return r
+}
+
+// Arg returns the argument, nil if it could not be added to the command.
+func (r *ArgRef[T]) Arg() *Arg {
+	return r.arg
+}
+
+// Get returns the value of the argument in the flags passed to the
+// handler, or the zero value when it is not passed.
+func (r *ArgRef[T]) Get(flags Flags) T {
+	var v T
+	if r.arg == nil {
+		return v
+	}
+	s, ok := flags[r.arg.name]
+	if !ok {
+		return v
+	}
+	v, _ = convert[T](s)
+	return v
+}
+
+// Value returns the value of the flag or the positional argument name
+// of the command converted to T, which is one of string, bool, int, the
+// sized integer types, float32, float64 or, for variadic arguments,
+// []string. For a flag with a custom type T is the type of its
+// FlagValue or of the value its Get method returns. An argument that
+// is not passed has the zero value.
+func Value[T any](c *Command, name string, flags Flags) (T, error) {
+	var v T
+	var err error
+	var s, dataType string
+	switch f, a := c.getFlag(name), c.getArg(name); {
+	case f != nil && f.custom != nil:
+		x, err := f.typedValue(flags)
+		if err != nil {
+			s, _ = f.lookup(flags)
+			return v, &FlagError{Command: c.Path(), Flag: f.name, Value: s, Type: f.dataType, Err: ErrInvalidValue}
+		}
+		if t, ok := customValue[T](x); ok {
+			return t, nil
+		}
+		return v, fmt.Errorf("%w %T for flag '%s' of type %s", errUnsupportedType, v, f.name, f.dataType)
+	case f != nil:
+		s, dataType = f.value(flags), f.dataType
+		if v, err = convert[T](s); err != nil && !errors.Is(err, errUnsupportedType) {
+			err = &FlagError{Command: c.Path(), Flag: f.name, Value: s, Type: dataType, Err: ErrInvalidValue}
+		}
+	case a != nil:
+		var ok bool
+		if s, ok = flags[a.name]; !ok {
+			return v, nil
+		}
+		dataType = a.dataType
+		if v, err = convert[T](s); err != nil && !errors.Is(err, errUnsupportedType) {
+			err = &ArgError{Command: c.Path(), Arg: a.name, Value: s, Type: dataType, Err: ErrInvalidValue}
+		}
+	default:
+		err = fmt.Errorf("command '%s' has no flag or argument '%s'", c.Path(), name)
+	}
+	return v, err
+}
+
+// customValue returns the FlagValue x as T, or the result of
+// its Get method when the FlagValue itself is not a T.
+func customValue[T any](x interface{}) (T, bool) {
+	if t, ok := x.(T); ok {
+		return t, true
+	}
+	t, ok := unwrapValue(x).(T)
+	return t, ok
+}
+
+// convert parses s as a value of type T. A slice of strings is
+// parsed as the quoted list variadic arguments are stored in.
+func convert[T any](s string) (T, error) {
+	var v T
+	var err error
+	switch p := any(&v).(type) {
+	case *string:
+		*p = s
+	case *int:
+		*p, err = strconv.Atoi(s)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+	case *bool:
+		*p, err = strconv.ParseBool(s)
+	case *[]string:
+		var tokens []parse.Token
+		tokens, err = parse.Tokenize(s)
+		for _, t := range tokens {
+			*p = append(*p, t.Value)
+		}
+	default:
+		// the sized integer types and float32
+		convert := getDataTypeFunction(reflect.TypeOf(&v).Elem().String())
+		if convert == nil {
+			err = fmt.Errorf("%w %T", errUnsupportedType, v)
+			break
+		}
+		var x interface{}
+		x, err = convert(s)
+		v, _ = x.(T)
+	}
+	return v, err
+}
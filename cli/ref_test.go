@@ -0,0 +1,101 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestFlagRef_Get(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	put := c.Simple("put", "puts", "puts files")
+	count := put.IntFlag("g", "int", 1, "a number", false)
+	ratio := put.FloatFlag("r", "ratio", 1e-9, "a ratio", false)
+	verbose := put.BoolFlag("v", "verbose", "verbose output")
+	name := put.StringFlag("n", "name", "none", "a name", false)
+	id := put.IntArg("id", "the id", true)
+	files := put.StringArgs("files", "the files", false)
+
+	var got []interface{}
+	put.Handler(func(flags cli.Flags) error {
+		got = []interface{}{count.Get(flags), ratio.Get(flags), verbose.Get(flags), name.Get(flags), id.Get(flags), files.Get(flags)}
+		return nil
+	})
+
+	var test = []struct {
+		line     string
+		expected []interface{}
+	}{
+		{"put 1 a 'b c' --int 7 -r 2.5 -v -n x", []interface{}{7, 2.5, true, "x", 1, []string{"a", "b c"}}},
+		{"put 2", []interface{}{1, 1e-9, false, "none", 2, []string(nil)}},
+	}
+	for _, tt := range test {
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Fatalf("%s: expected no error, got '%v'", tt.line, err)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.line, tt.expected, got)
+		}
+	}
+
+	if count.Flag() != put.Lookup("g") || id.Arg() != put.Args()[0] {
+		t.Errorf("expected the handles to refer to their flag and argument")
+	}
+	if clash := put.IntFlag("id", "", 0, "clashes with the argument", false); clash.Flag() != nil || clash.Get(cli.Flags{"id": "3"}) != 0 {
+		t.Errorf("expected an empty handle for a flag that was not added")
+	}
+}
+
+func TestValue(t *testing.T) {
+	c := cli.New()
+	get := c.Simple("get", "gets", "gets files")
+	get.IntFlag("g", "int", 1, "a number", false)
+	get.Flag("l", "float", "float", 1.5, "a float", false)
+	get.FloatFlag("e", "eps", 1e-9, "a tolerance", false)
+	get.Flag("s", "size", "uint64", uint64(1<<40), "a size", false)
+	get.Flag("r", "ratio", "float32", float32(0.1), "a ratio", false)
+	get.Flag("b", "byte", "int8", nil, "a byte", false)
+	get.IntArg("id", "the id", false)
+	flags := cli.Flags{"int": "4", "id": "x", "b": "300"}
+
+	if v, err := cli.Value[int](get, "g", flags); err != nil || v != 4 {
+		t.Errorf("expected 4, got %v, '%v'", v, err)
+	}
+	if v, err := cli.Value[float64](get, "l", flags); err != nil || v != 1.5 {
+		t.Errorf("expected the default 1.5 of the float flag, got %v, '%v'", v, err)
+	}
+	if v, err := cli.Value[float64](get, "eps", flags); err != nil || v != 1e-9 {
+		t.Errorf("expected the exact default 1e-9, got %v, '%v'", v, err)
+	}
+	if v, err := cli.Value[uint64](get, "s", flags); err != nil || v != 1<<40 {
+		t.Errorf("expected the default 1<<40 of the uint64 flag, got %v, '%v'", v, err)
+	}
+	if v, err := cli.Value[float32](get, "ratio", flags); err != nil || v != 0.1 {
+		t.Errorf("expected the exact default 0.1 of the float32 flag, got %v, '%v'", v, err)
+	}
+	if _, err := cli.Value[int8](get, "b", flags); !errors.Is(err, cli.ErrInvalidValue) {
+		t.Errorf("expected invalid value for an overflowing int8, got '%v'", err)
+	}
+	if v, err := c.FlagValue("get", "l", flags); err != nil || v != 1.5 {
+		t.Errorf("expected FlagValue to convert the float flag, got %v, '%v'", v, err)
+	}
+	if _, err := cli.Value[int](get, "id", flags); !errors.Is(err, cli.ErrInvalidValue) {
+		t.Errorf("expected invalid value for the argument, got '%v'", err)
+	}
+	if v, err := cli.Value[int](get, "id", cli.Flags{}); err != nil || v != 0 {
+		t.Errorf("expected the zero value for a missing argument, got %v, '%v'", v, err)
+	}
+	if _, err := cli.Value[[]int](get, "g", flags); err == nil || errors.Is(err, cli.ErrInvalidValue) {
+		t.Errorf("expected an unsupported type error, got '%v'", err)
+	}
+	if _, err := cli.Value[string](get, "missing", flags); err == nil {
+		t.Errorf("expected an error for an unknown name")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// completeCommand is the hidden entry point the completion scripts call
+// with the words of the command line, the last one being completed.
+const completeCommand = "__complete"
+
+var completionScripts = map[string]*template.Template{
+	"bash": template.Must(template.New("bash").Parse(`# bash completion for {{.Name}}
+_{{.Func}}_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($({{.Name}} ` + completeCommand + ` "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]]; then
+        compopt -o nospace 2>/dev/null
+    fi
+}
+complete -F _{{.Func}}_complete {{.Name}}
+`)),
+	"zsh": template.Must(template.New("zsh").Parse(`#compdef {{.Name}}
+# zsh completion for {{.Name}}
+_{{.Func}}() {
+    local -a candidates
+    candidates=(${(f)"$({{.Name}} ` + completeCommand + ` "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    compadd -- $candidates
+}
+compdef _{{.Func}} {{.Name}}
+`)),
+	"fish": template.Must(template.New("fish").Parse(`# fish completion for {{.Name}}
+function __{{.Func}}_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    {{.Name}} ` + completeCommand + ` $words (commandline -ct) 2>/dev/null
+end
+complete -c {{.Name}} -f -a '(__{{.Func}}_complete)'
+`)),
+	"powershell": template.Must(template.New("powershell").Parse(`# powershell completion for {{.Name}}
+Register-ArgumentCompleter -Native -CommandName '{{.Name}}' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') {
+        $words += '""'
+    }
+    & '{{.Name}}' ` + completeCommand + ` @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`)),
+}
+
+// nonIdentifier matches the characters not allowed in shell function names
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// GenerateCompletion writes the completion script of the program for
+// shell, one of bash, zsh, fish and powershell. The script calls the
+// program with the hidden __complete command, so the program must pass
+// its arguments to Execute when it is run with arguments.
+func (cli *CLI) GenerateCompletion(w io.Writer, shell string) error {
+	t, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("no completion script for shell '%s', expecting one of bash, zsh, fish, powershell", shell)
+	}
+	name := filepath.Base(cli.name)
+	return t.Execute(w, struct {
+		Name string
+		Func string
+	}{
+		Name: name,
+		Func: nonIdentifier.ReplaceAllString(strings.TrimSuffix(name, filepath.Ext(name)), "_"),
+	})
+}
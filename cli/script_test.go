@@ -0,0 +1,59 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCLI_GenerateCompletion(t *testing.T) {
+	c := cli.New(cli.WithName("/usr/local/bin/my-app"))
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		buf := new(bytes.Buffer)
+		if err := c.GenerateCompletion(buf, shell); err != nil {
+			t.Errorf("%s: expected no error, got '%v'", shell, err)
+		}
+		for _, s := range []string{"my-app", "__complete"} {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("%s: expected script to contain '%s', got:\n%s", shell, s, buf.String())
+			}
+		}
+	}
+	if err := c.GenerateCompletion(new(bytes.Buffer), "tcsh"); err == nil {
+		t.Errorf("expected error for unknown shell")
+	}
+}
+
+func TestCLI_ExecuteComplete(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := cli.New(cli.WithOutput(out))
+	get := c.Simple("get", "gets", "gets")
+	get.StringFlag("f", "format", "", "", false)
+	get.Completion("f", cli.CompleteValues("json", "table"))
+	c.Simple("remote", "", "")
+
+	var tests = []struct {
+		line   string
+		output string
+	}{
+		{"__complete ''", "get\nhelp\nremote\n"},
+		{"__complete get -f j", "json\n"},
+		{"__complete get --", "--format\n--help\n"},
+		{"__complete unknown ''", ""},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		if _, err := c.Execute(tt.line); err != nil {
+			t.Errorf("expected no error, got '%v'", err)
+		}
+		if out.String() != tt.output {
+			t.Errorf("'%s': expected %q, got %q", tt.line, tt.output, out.String())
+		}
+	}
+}
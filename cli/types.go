@@ -0,0 +1,370 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getter is implemented by the values that hold a plain Go value,
+// like flag.Getter of the standard library. The handlers get the
+// result of Get instead of the FlagValue.
+type getter interface {
+	Get() interface{}
+}
+
+// formatter is implemented by the values that describe the format
+// they expect, which is shown in the help of the flag.
+type formatter interface {
+	Format() string
+}
+
+// unwrapValue returns the plain Go value of a FlagValue that has one
+func unwrapValue(v interface{}) interface{} {
+	if g, ok := v.(getter); ok {
+		return g.Get()
+	}
+	return v
+}
+
+// durationValue parses durations such as 30s or 1h15m
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string   { return time.Duration(*d).String() }
+func (d *durationValue) Type() string     { return "duration" }
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+func (d *durationValue) Format() string   { return "e.g. 300ms, 30s, 1h15m" }
+
+// timeValue parses timestamps with a layout of the time package
+type timeValue struct {
+	t      time.Time
+	layout string
+}
+
+func (v *timeValue) Set(s string) error {
+	t, err := time.Parse(v.layout, s)
+	if err != nil {
+		return err
+	}
+	v.t = t
+	return nil
+}
+
+func (v *timeValue) String() string {
+	if v.t.IsZero() {
+		return ""
+	}
+	return v.t.Format(v.layout)
+}
+
+func (v *timeValue) Type() string     { return "time" }
+func (v *timeValue) Get() interface{} { return v.t }
+func (v *timeValue) Format() string   { return v.layout }
+
+// ipValue parses IPv4 and IPv6 addresses
+type ipValue struct {
+	ip net.IP
+}
+
+func (v *ipValue) Set(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %s", s)
+	}
+	v.ip = ip
+	return nil
+}
+
+func (v *ipValue) String() string {
+	if v.ip == nil {
+		return ""
+	}
+	return v.ip.String()
+}
+
+func (v *ipValue) Type() string     { return "ip" }
+func (v *ipValue) Get() interface{} { return v.ip }
+func (v *ipValue) Format() string   { return "e.g. 192.168.0.1, ::1" }
+
+// cidrValue parses networks in CIDR notation
+type cidrValue struct {
+	network *net.IPNet
+}
+
+func (v *cidrValue) Set(s string) error {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return err
+	}
+	v.network = network
+	return nil
+}
+
+func (v *cidrValue) String() string {
+	if v.network == nil {
+		return ""
+	}
+	return v.network.String()
+}
+
+func (v *cidrValue) Type() string     { return "cidr" }
+func (v *cidrValue) Get() interface{} { return v.network }
+func (v *cidrValue) Format() string   { return "e.g. 10.0.0.0/8, fd00::/8" }
+
+// urlValue parses absolute URLs
+type urlValue struct {
+	u *url.URL
+}
+
+func (v *urlValue) Set(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("URL %s is not absolute", s)
+	}
+	v.u = u
+	return nil
+}
+
+func (v *urlValue) String() string {
+	if v.u == nil {
+		return ""
+	}
+	return v.u.String()
+}
+
+func (v *urlValue) Type() string     { return "url" }
+func (v *urlValue) Get() interface{} { return v.u }
+func (v *urlValue) Format() string   { return "e.g. https://example.com/path" }
+
+// sizeUnits are the multipliers of the byte size suffixes
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// sizeValue parses byte sizes such as 512, 10KB or 1.5GiB
+type sizeValue int64
+
+func (v *sizeValue) Set(s string) error {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return fmt.Errorf("unknown size unit in %s", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %s", s)
+	}
+	size := n * float64(unit)
+	if size > math.MaxInt64 {
+		return fmt.Errorf("size %s is too large", s)
+	}
+	*v = sizeValue(size)
+	return nil
+}
+
+// String renders the size with the largest binary unit dividing it
+func (v *sizeValue) String() string {
+	n := int64(*v)
+	for _, u := range []string{"TiB", "GiB", "MiB", "KiB"} {
+		if m := sizeUnits[strings.ToLower(u)]; n != 0 && n%m == 0 {
+			return fmt.Sprintf("%d%s", n/m, u)
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func (v *sizeValue) Type() string     { return "size" }
+func (v *sizeValue) Get() interface{} { return int64(*v) }
+func (v *sizeValue) Format() string   { return "bytes or e.g. 10KB, 1.5GiB" }
+
+// regexpValue compiles regular expressions
+type regexpValue struct {
+	re *regexp.Regexp
+}
+
+func (v *regexpValue) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	v.re = re
+	return nil
+}
+
+func (v *regexpValue) String() string {
+	if v.re == nil {
+		return ""
+	}
+	return v.re.String()
+}
+
+func (v *regexpValue) Type() string     { return "regexp" }
+func (v *regexpValue) Get() interface{} { return v.re }
+
+// pathValue holds the path of an existing file or directory
+type pathValue struct {
+	path string
+	dir  bool
+}
+
+func (v *pathValue) Set(s string) error {
+	info, err := os.Stat(s)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() != v.dir {
+		if v.dir {
+			return fmt.Errorf("%s is not a directory", s)
+		}
+		return fmt.Errorf("%s is a directory", s)
+	}
+	v.path = s
+	return nil
+}
+
+func (v *pathValue) String() string { return v.path }
+
+func (v *pathValue) Type() string {
+	if v.dir {
+		return "dir"
+	}
+	return "file"
+}
+
+func (v *pathValue) Get() interface{} { return v.path }
+
+// DurationFlag adds a flag taking a duration such as 30s or 1h15m.
+func (c *Command) DurationFlag(name, alias string, defaultValue time.Duration, description string, isRequired bool) *FlagRef[time.Duration] {
+	v := durationValue(defaultValue)
+	return newFlagRef[time.Duration](c, c.Var(&v, name, alias, description, isRequired), name)
+}
+
+// TimeFlag adds a flag taking a timestamp in the layout of the time
+// package, time.RFC3339 when layout is empty.
+func (c *Command) TimeFlag(name, alias, layout string, defaultValue time.Time, description string, isRequired bool) *FlagRef[time.Time] {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	v := &timeValue{t: defaultValue, layout: layout}
+	return newFlagRef[time.Time](c, c.Var(v, name, alias, description, isRequired), name)
+}
+
+// IPFlag adds a flag taking an IPv4 or IPv6 address.
+func (c *Command) IPFlag(name, alias string, defaultValue net.IP, description string, isRequired bool) *FlagRef[net.IP] {
+	v := &ipValue{ip: defaultValue}
+	return newFlagRef[net.IP](c, c.Var(v, name, alias, description, isRequired), name)
+}
+
+// CIDRFlag adds a flag taking a network in CIDR notation.
+func (c *Command) CIDRFlag(name, alias string, defaultValue *net.IPNet, description string, isRequired bool) *FlagRef[*net.IPNet] {
+	v := &cidrValue{network: defaultValue}
+	return newFlagRef[*net.IPNet](c, c.Var(v, name, alias, description, isRequired), name)
+}
+
+// URLFlag adds a flag taking an absolute URL.
+func (c *Command) URLFlag(name, alias string, defaultValue *url.URL, description string, isRequired bool) *FlagRef[*url.URL] {
+	v := &urlValue{u: defaultValue}
+	return newFlagRef[*url.URL](c, c.Var(v, name, alias, description, isRequired), name)
+}
+
+// SizeFlag adds a flag taking a size in bytes, with an optional decimal
+// (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) unit.
+func (c *Command) SizeFlag(name, alias string, defaultValue int64, description string, isRequired bool) *FlagRef[int64] {
+	v := sizeValue(defaultValue)
+	return newFlagRef[int64](c, c.Var(&v, name, alias, description, isRequired), name)
+}
+
+// RegexpFlag adds a flag taking a regular expression.
+func (c *Command) RegexpFlag(name, alias string, defaultValue *regexp.Regexp, description string, isRequired bool) *FlagRef[*regexp.Regexp] {
+	v := &regexpValue{re: defaultValue}
+	return newFlagRef[*regexp.Regexp](c, c.Var(v, name, alias, description, isRequired), name)
+}
+
+// ExistingFileFlag adds a flag taking the path of a file that exists.
+// Its values are completed with the files on Tab.
+func (c *Command) ExistingFileFlag(name, alias, defaultValue, description string, isRequired bool) *FlagRef[string] {
+	return c.pathFlag(name, alias, defaultValue, description, isRequired, false)
+}
+
+// ExistingDirFlag adds a flag taking the path of a directory that exists.
+// Its values are completed with the files on Tab.
+func (c *Command) ExistingDirFlag(name, alias, defaultValue, description string, isRequired bool) *FlagRef[string] {
+	return c.pathFlag(name, alias, defaultValue, description, isRequired, true)
+}
+
+func (c *Command) pathFlag(name, alias, defaultValue, description string, isRequired, dir bool) *FlagRef[string] {
+	v := &pathValue{path: defaultValue, dir: dir}
+	r := newFlagRef[string](c, c.Var(v, name, alias, description, isRequired), name)
+	if r.flag != nil {
+		r.flag.complete = CompleteFiles
+	}
+	return r
+}
+
+// builtinValue returns a new value for the struct fields of the types
+// that have a built-in flag type, or nil for the other types.
+func builtinValue(t reflect.Type) FlagValue {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return new(durationValue)
+	case reflect.TypeOf(time.Time{}):
+		return &timeValue{layout: time.RFC3339}
+	case reflect.TypeOf(net.IP(nil)):
+		return new(ipValue)
+	case reflect.TypeOf((*net.IPNet)(nil)):
+		return new(cidrValue)
+	case reflect.TypeOf((*url.URL)(nil)):
+		return new(urlValue)
+	case reflect.TypeOf((*regexp.Regexp)(nil)):
+		return new(regexpValue)
+	case reflect.TypeOf([]string(nil)):
+		return new(stringSliceValue)
+	case reflect.TypeOf([]int(nil)):
+		return new(intSliceValue)
+	case reflect.TypeOf(map[string]string(nil)):
+		return new(stringMapValue)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_RichFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-icls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	fetch := c.Simple("fetch", "fetches", "fetches a resource")
+	timeout := fetch.DurationFlag("t", "timeout", 30*time.Second, "the timeout", false)
+	since := fetch.TimeFlag("s", "since", "2006-01-02", time.Time{}, "the first day", false)
+	ip := fetch.IPFlag("i", "ip", nil, "the address", false)
+	cidr := fetch.CIDRFlag("n", "net", nil, "the network", false)
+	u := fetch.URLFlag("u", "url", nil, "the url", false)
+	max := fetch.SizeFlag("m", "max", 1<<20, "the largest size", false)
+	re := fetch.RegexpFlag("r", "match", nil, "the pattern", false)
+	conf := fetch.ExistingFileFlag("c", "config", "", "the config file", false)
+	out := fetch.ExistingDirFlag("o", "out", "", "the output directory", false)
+
+	var passed cli.Flags
+	fetch.Handler(func(flags cli.Flags) error {
+		passed = flags
+		return nil
+	})
+
+	line := "fetch -t 1m30s -s 2020-03-04 -i ::1 -n 10.1.0.0/16 -u https://example.com/a -m 1.5KiB -r ^a+$ -c " + file + " -o " + dir
+	if _, err := c.Execute(line); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	got := []string{timeout.Get(passed).String(), since.Get(passed).Format("Jan 2"), ip.Get(passed).String(),
+		cidr.Get(passed).String(), u.Get(passed).Host, time.Duration(max.Get(passed)).String(), re.Get(passed).String(),
+		conf.Get(passed), out.Get(passed)}
+	expected := []string{"1m30s", "Mar 4", "::1", "10.1.0.0/16", "example.com", "1.536µs", "^a+$", file, dir}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if _, err := c.Execute("fetch"); err != nil || timeout.Get(passed) != 30*time.Second || max.Get(passed) != 1<<20 || re.Get(passed) != nil {
+		t.Errorf("expected the default values, got '%v'", err)
+	}
+
+	var test = []string{
+		"fetch -t 30", "fetch -s 04/03/2020", "fetch -i 300.1.1.1", "fetch -n 10.1.0.0",
+		"fetch -u example.com", "fetch -m 10XB", "fetch -r a(", "fetch -c " + dir, "fetch -o " + file,
+		"fetch -c " + filepath.Join(dir, "missing"),
+	}
+	for _, line := range test {
+		if _, err := c.Execute(line); !errors.Is(err, cli.ErrInvalidValue) {
+			t.Errorf("%s: expected invalid value error, got '%v'", line, err)
+		}
+	}
+	if _, err := c.Execute("fetch -m 10XB"); err == nil || !strings.HasSuffix(err.Error(), ": unknown size unit in 10XB") {
+		t.Errorf("expected the reason of the invalid size, got '%v'", err)
+	}
+
+	help := fetch.String()
+	for _, s := range []string{"--timeout <duration>", "(format: e.g. 300ms, 30s, 1h15m) (default: 30s)",
+		"--since <time>", "(format: 2006-01-02)", "--max <size>", "(default: 1MiB)", "--config <file>"} {
+		if !strings.Contains(help, s) {
+			t.Errorf("expected help to contain '%s', got:\n%s", s, help)
+		}
+	}
+}
+
+func TestCLI_NewStructRichTypes(t *testing.T) {
+	type options struct {
+		Timeout time.Duration `flag:"t" default:"5s"`
+		Addr    net.IP        `flag:"a"`
+	}
+	c := cli.New()
+	var got *options
+	if _, err := c.NewStruct("dial", "dials", "dials", func(o *options) error {
+		got = o
+		return nil
+	}); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	if _, err := c.Execute("dial -a 10.0.0.1"); err != nil || got.Timeout != 5*time.Second || got.Addr.String() != "10.0.0.1" {
+		t.Errorf("expected 5s and 10.0.0.1, got %+v, '%v'", got, err)
+	}
+}
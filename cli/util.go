@@ -5,9 +5,25 @@
 package cli
 
 import (
+	"reflect"
+	"sort"
 	"strconv"
 )
 
+// sizedTypes are the integer types of a given size and
+// sign, converted to the same type by getDataTypeFunction
+var sizedTypes = map[string]reflect.Type{
+	"int8":   reflect.TypeOf(int8(0)),
+	"int16":  reflect.TypeOf(int16(0)),
+	"int32":  reflect.TypeOf(int32(0)),
+	"int64":  reflect.TypeOf(int64(0)),
+	"uint":   reflect.TypeOf(uint(0)),
+	"uint8":  reflect.TypeOf(uint8(0)),
+	"uint16": reflect.TypeOf(uint16(0)),
+	"uint32": reflect.TypeOf(uint32(0)),
+	"uint64": reflect.TypeOf(uint64(0)),
+}
+
 func getDataTypeFunction(dt string) func(string) (interface{}, error) {
 	switch dt {
 	case "int":
@@ -24,16 +40,41 @@ func getDataTypeFunction(dt string) func(string) (interface{}, error) {
 			b, err := strconv.ParseBool(s)
 			return bool(b), err
 		}
-	case "float":
+	case "float", "float64":
 		return func(s string) (interface{}, error) {
 			f, err := strconv.ParseFloat(s, 64)
 			return float64(f), err
 		}
+	case "float32":
+		return func(s string) (interface{}, error) {
+			f, err := strconv.ParseFloat(s, 32)
+			return float32(f), err
+		}
+	case "int8", "int16", "int32", "int64":
+		t := sizedTypes[dt]
+		return func(s string) (interface{}, error) {
+			i, err := strconv.ParseInt(s, 10, t.Bits())
+			return reflect.ValueOf(i).Convert(t).Interface(), err
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		t := sizedTypes[dt]
+		return func(s string) (interface{}, error) {
+			u, err := strconv.ParseUint(s, 10, t.Bits())
+			return reflect.ValueOf(u).Convert(t).Interface(), err
+		}
 	default:
 		return nil
 	}
 }
 
+// canonicalType returns the name data types are stored with
+func canonicalType(dt string) string {
+	if dt == "float" {
+		return "float64"
+	}
+	return dt
+}
+
 func conv(t string, f func(s string) (interface{}, error)) (interface{}, error) {
 	return f(t)
 }
@@ -48,3 +89,52 @@ func checkForKeysInMap(m Flags, keys ...string) bool {
 	}
 	return false
 }
+
+// maxSuggestionDistance is the largest edit distance of a suggestion
+const maxSuggestionDistance = 2
+
+// suggest returns the candidate closest to name by edit distance,
+// or "" when none of them is close enough to be a likely typo.
+func suggest(name string, candidates []string) string {
+	sort.Strings(candidates)
+	best, bestDistance := "", maxSuggestionDistance+1
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		// a distance as long as the name would suggest anything
+		if d := levenshtein(name, c); d < bestDistance && d < len([]rune(name)) {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the number of single rune insertions,
+// deletions and substitutions that turn a into b.
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < curr[j] {
+				curr[j] = d
+			}
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(t)]
+}
@@ -73,3 +73,24 @@ func TestConv(t *testing.T) {
 		}
 	}
 }
+
+func TestSuggest(t *testing.T) {
+	var test = []struct {
+		name       string
+		candidates []string
+		out        string
+	}{
+		{"verbos", []string{"verbose", "version", "v"}, "verbose"},
+		{"gte", []string{"get", "put"}, "get"},
+		{"stat", []string{"status", "start"}, "start"},
+		{"deploy", []string{"get", "put"}, ""},
+		{"émoji", []string{"emoji"}, "emoji"},
+		{"x", nil, ""},
+	}
+
+	for _, tt := range test {
+		if out := suggest(tt.name, tt.candidates); out != tt.out {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.out, out)
+		}
+	}
+}
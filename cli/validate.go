@@ -0,0 +1,62 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+// Validate adds a func checking the value of the flag whenever it is
+// passed or set by its environment variable. The validators run before
+// the handler, after the value is converted to the type of the flag,
+// and an error fails the command with an ErrInvalidValue FlagError
+// holding the error as its Reason.
+//
+//	port := serve.IntFlag("p", "port", 8080, "the port", false)
+//	port.Validate(func(p int) error {
+//		if p < 1 || p > 65535 {
+//			return errors.New("must be between 1 and 65535")
+//		}
+//		return nil
+//	})
+func (r *FlagRef[T]) Validate(validate func(value T) error) *FlagRef[T] {
+	if r.flag != nil {
+		r.flag.validators = append(r.flag.validators, func(flags Flags) error {
+			return validate(r.Get(flags))
+		})
+	}
+	return r
+}
+
+// Validate adds a func checking the command before its handler runs,
+// once the flags and the arguments are valid, e.g. for checks that
+// involve several of them. The errors of all the validators are
+// reported together in a ValidationError.
+func (c *Command) Validate(validate func(flags Flags) error) {
+	c.validators = append(c.validators, validate)
+}
+
+// validate runs the validators of a flag set to v
+func (f *Flag) validate(cmd *Command, v string, flags Flags) []error {
+	var errs []error
+	for _, validate := range f.validators {
+		if err := validate(flags); err != nil {
+			errs = append(errs, &FlagError{Command: cmd.Path(), Flag: f.name, Value: v,
+				Err: ErrInvalidValue, Reason: err})
+		}
+	}
+	return errs
+}
+
+// runValidators runs the validators of the command, reporting
+// their errors in a ValidationError
+func (c *Command) runValidators(flags Flags) error {
+	var errs []error
+	for _, validate := range c.validators {
+		if err := validate(flags); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Command: c.Path(), Errors: errs}
+}
@@ -0,0 +1,89 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCommand_Validate(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	ran := false
+	serve := c.New("serve", "serves", "serves the files", func(flags cli.Flags) error {
+		ran = true
+		return nil
+	})
+	errRange := errors.New("must be between 1 and 65535")
+	port := serve.IntFlag("p", "port", 8080, "the port", false).Validate(func(p int) error {
+		if p < 1 || p > 65535 {
+			return errRange
+		}
+		return nil
+	})
+	host := serve.StringFlag("host", "", "localhost", "the host", false).Validate(func(h string) error {
+		if strings.ContainsAny(h, " /") {
+			return errors.New("must be a host name")
+		}
+		return nil
+	})
+	dir := serve.StringArg("dir", "the directory", false)
+	serve.Validate(func(flags cli.Flags) error {
+		if port.Get(flags) < 1024 && host.Get(flags) != "localhost" {
+			return fmt.Errorf("port %d is privileged on %s", port.Get(flags), host.Get(flags))
+		}
+		return nil
+	})
+	serve.Validate(func(flags cli.Flags) error {
+		if dir.Get(flags) == "/" {
+			return errors.New("cannot serve the root directory")
+		}
+		return nil
+	})
+
+	var test = []struct {
+		line string
+		msg  string
+	}{
+		{"serve", ""},
+		{"serve www -p 80", ""},
+		{"serve -p 70000", "invalid value '70000' for flag 'p' of command 'serve': must be between 1 and 65535"},
+		{"serve -p 0", "invalid value '0' for flag 'p' of command 'serve': must be between 1 and 65535"},
+		{"serve -p x", "invalid value 'x' for flag 'p' of command 'serve', expected int"},
+		{"serve -p 0 --host 'a b'", "invalid flags of command 'serve':" +
+			"\n\tinvalid value 'a b' for flag 'host': must be a host name" +
+			"\n\tinvalid value '0' for flag 'p': must be between 1 and 65535"},
+		{"serve / -p 80 --host example.com", "invalid flags of command 'serve':" +
+			"\n\tport 80 is privileged on example.com" +
+			"\n\tcannot serve the root directory"},
+	}
+	for _, tt := range test {
+		ran = false
+		_, err := c.Execute(tt.line)
+		if tt.msg == "" {
+			if err != nil || !ran {
+				t.Errorf("%s: expected the handler to run, got '%v'", tt.line, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.msg {
+			t.Errorf("%s: expected error \"%s\", got \"%v\"", tt.line, tt.msg, err)
+		}
+		if ran {
+			t.Errorf("%s: expected the handler not to run", tt.line)
+		}
+	}
+
+	_, err := c.Execute("serve -p 0")
+	var fe *cli.FlagError
+	if !errors.Is(err, cli.ErrInvalidValue) || !errors.As(err, &fe) || fe.Reason != errRange {
+		t.Errorf("expected an invalid value with the validator error as its reason, got '%v'", err)
+	}
+}
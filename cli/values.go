@@ -0,0 +1,134 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+// Values holds the flags and the positional arguments of an executed
+// command converted to their data types. Flags are found by name and
+// by alias and the flags that were not passed hold their default value.
+// Variadic arguments hold a slice of their values.
+type Values map[string]interface{}
+
+// Get returns the value of a flag or an argument, nil if there is none.
+// Flags with a custom type hold a copy of their FlagValue, or the
+// result of its Get() interface{} method when it has one, e.g. a
+// time.Duration for the flags added with DurationFlag.
+func (v Values) Get(name string) interface{} {
+	return v[name]
+}
+
+// Has reports whether the flag or the argument has a value.
+func (v Values) Has(name string) bool {
+	_, ok := v[name]
+	return ok
+}
+
+// String returns the value of a string flag or argument.
+func (v Values) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+// Int returns the value of an int flag or argument.
+func (v Values) Int(name string) int {
+	switch i := v[name].(type) {
+	case int:
+		return i
+	case int64:
+		return int(i)
+	}
+	return 0
+}
+
+// Float returns the value of a float flag or argument.
+func (v Values) Float(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+// Bool returns the value of a bool flag.
+func (v Values) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+// Strings returns the values of a variadic argument.
+func (v Values) Strings(name string) []string {
+	values, _ := v[name].([]interface{})
+	s := make([]string, len(values))
+	for i, value := range values {
+		s[i] = fmt.Sprint(value)
+	}
+	return s
+}
+
+// NewValues creates a command with a handler that takes the typed values.
+func (cli *CLI) NewValues(name, shortDesc, description string, handler func(ctx context.Context, values Values) error) *Command {
+	cmd := cli.NewContext(name, shortDesc, description, nil)
+	cmd.ValuesHandler(handler)
+	return cmd
+}
+
+// NewValues creates a subcommand with a handler that takes the typed values.
+func (c *Command) NewValues(name, shortDesc, description string, handler func(ctx context.Context, values Values) error) *Command {
+	cmd := c.NewContext(name, shortDesc, description, nil)
+	cmd.ValuesHandler(handler)
+	return cmd
+}
+
+// ValuesHandler sets a handler that takes the values of the flags and
+// the arguments converted to their data types. The values are checked
+// before the handler runs, so it does not deal with conversion errors.
+func (c *Command) ValuesHandler(h func(ctx context.Context, values Values) error) {
+	c.handler = func(ctx context.Context, flags Flags) error {
+		return h(ctx, c.values(flags))
+	}
+}
+
+// values converts the flags and the arguments passed to the command,
+// which have already been validated, to their data types.
+func (c *Command) values(flags Flags) Values {
+	values := make(Values)
+	for _, f := range c.flags {
+		v, err := f.typedValue(flags)
+		if err != nil {
+			continue
+		}
+		v = unwrapValue(v)
+		values[f.name] = v
+		if f.alias != "" {
+			values[f.alias] = v
+		}
+	}
+
+	for _, a := range c.args {
+		s, ok := flags[a.name]
+		if !ok {
+			continue
+		}
+		convert := getDataTypeFunction(a.dataType)
+		if !a.isVariadic {
+			if v, err := convert(s); err == nil {
+				values[a.name] = v
+			}
+			continue
+		}
+		tokens, _ := parse.Tokenize(s)
+		list := make([]interface{}, 0, len(tokens))
+		for _, t := range tokens {
+			if v, err := convert(t.Value); err == nil {
+				list = append(list, v)
+			}
+		}
+		values[a.name] = list
+	}
+	return values
+}
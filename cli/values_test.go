@@ -0,0 +1,69 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/cli"
+)
+
+func TestCLI_NewValues(t *testing.T) {
+	c := cli.New(cli.WithOutput(ioutil.Discard), cli.WithErrorOutput(ioutil.Discard))
+	var got cli.Values
+	called := false
+	put := c.NewValues("put", "puts", "puts files", func(ctx context.Context, values cli.Values) error {
+		called = true
+		got = values
+		return nil
+	})
+	put.IntFlag("g", "int", 1, "a number", false)
+	put.FloatFlag("r", "ratio", 0.5, "a ratio", false)
+	put.BoolFlag("v", "verbose", "verbose output")
+	put.StringFlag("m", "message", "none", "a message", false)
+	put.FloatFlag("e", "eps", 1e-7, "a tolerance", false)
+	put.Flag("s", "", "string", nil, "no default", false)
+	put.IntArg("count", "the count", true)
+	put.StringArgs("files", "the files", false)
+
+	if _, err := c.Execute("put 3 a 'b c' --int 7 -v"); err != nil {
+		t.Fatalf("expected no error, got '%v'", err)
+	}
+	if got.Int("g") != 7 || got.Int("int") != 7 {
+		t.Errorf("expected int flag 7 by name and alias, got %v", got)
+	}
+	if got.Float("ratio") != 0.5 || got.String("m") != "none" {
+		t.Errorf("expected the default values, got %v", got)
+	}
+	if got.Float("eps") != 1e-7 || got.String("s") != "" {
+		t.Errorf("expected the exact and the nil default values, got %v", got)
+	}
+	if !got.Bool("verbose") {
+		t.Errorf("expected bool flag to be true, got %v", got)
+	}
+	if got.Int("count") != 3 {
+		t.Errorf("expected argument 3, got %v", got.Get("count"))
+	}
+	if files := got.Strings("files"); !reflect.DeepEqual(files, []string{"a", "b c"}) {
+		t.Errorf("expected variadic argument [a b c], got %v", files)
+	}
+
+	called = false
+	_, err := c.Execute("put 3 -g abc")
+	var flagErr *cli.FlagError
+	if !errors.As(err, &flagErr) || flagErr.Flag != "g" || flagErr.Type != "int" {
+		t.Errorf("expected invalid value error for 'g', got '%v'", err)
+	}
+	if called {
+		t.Errorf("expected the handler not to run with an invalid value")
+	}
+	if _, err := c.Execute("put 3"); err != nil || got.Has("files") || got.Bool("v") {
+		t.Errorf("expected no files and verbose off, got %v, %v", got, err)
+	}
+}
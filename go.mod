@@ -1,3 +1,3 @@
 module github.com/RomanosTrechlis/go-icls
 
-go 1.15
+go 1.21
@@ -0,0 +1,140 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Spec describes the flags of a command to ParseFlags, so that
+// the values are consumed according to the type of the flags.
+type Spec struct {
+	// Known reports whether key is a flag of the command.
+	// When nil no flag is known.
+	Known func(key string) bool
+	// Bool reports whether the flag key takes no value.
+	// When nil every flag takes a value.
+	Bool func(key string) bool
+}
+
+func (s Spec) known(key string) bool {
+	return s.Known != nil && s.Known(key)
+}
+
+func (s Spec) isBool(key string) bool {
+	return s.Bool != nil && s.Bool(key)
+}
+
+// ParseFlags parses the words that follow a command with the syntax of
+// GNU getopt, returning the flags and the positional arguments found in
+// any order among them. The Command of the result is empty.
+//
+//	--key value, --key=value  long flags
+//	-k value, -kvalue         short flags taking a value
+//	-abc                      the bool flags a, b and c
+//	-vofile                   the bool flag v and the flag o set to file
+//	--                        the words that follow are arguments
+//
+// A flag that is not bool takes the next word as its value unless it is
+// a flag itself, while a negative number such as -5 is always a value.
+// A bool flag never takes the next word, so its value is only given as
+// in --verbose=false. A single dash word that is a known flag is taken
+// whole, so flags longer than one letter may still be passed as -name.
+func ParseFlags(tokens []Token, spec Spec) *Result {
+	r := &Result{Flags: make(map[string]string)}
+	add := func(key, value string) {
+		r.Flags[key] = value
+		r.Occurrences = append(r.Occurrences, Flag{Key: key, Value: value})
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		next := tokens[i+1:]
+		switch {
+		case isTerminator(t):
+			for _, rest := range next {
+				r.Args = append(r.Args, rest.Value)
+			}
+			return r
+		case !t.IsFlag() || isNumber(t.Value):
+			r.Args = append(r.Args, t.Value)
+		case strings.HasPrefix(t.Value, "--"):
+			key := t.Value[2:]
+			if j := strings.Index(key, "="); j > 0 {
+				add(key[:j], key[j+1:])
+				continue
+			}
+			value, n := spec.value(key, next)
+			add(key, value)
+			i += n
+		default:
+			key := t.Value[1:]
+			first, size := utf8.DecodeRuneInString(key)
+			if spec.known(key) || !spec.known(string(first)) {
+				value, n := spec.value(key, next)
+				add(key, value)
+				i += n
+				continue
+			}
+			i += spec.cluster(key, first, size, next, add)
+		}
+	}
+	return r
+}
+
+// cluster parses combined short flags, e.g. -abc or -vofile, and
+// returns the number of following words consumed as a value.
+func (s Spec) cluster(key string, first rune, size int, next []Token, add func(key, value string)) int {
+	for {
+		k, rest := string(first), key[size:]
+		if !s.isBool(k) {
+			if rest != "" {
+				add(k, rest)
+				return 0
+			}
+			value, n := s.value(k, next)
+			add(k, value)
+			return n
+		}
+		add(k, "")
+		if rest == "" {
+			return 0
+		}
+		key = rest
+		first, size = utf8.DecodeRuneInString(key)
+	}
+}
+
+// value returns the value of the flag key taken from the next
+// words and the number of words it took, which is 0 or 1.
+func (s Spec) value(key string, next []Token) (string, int) {
+	if len(next) == 0 || isTerminator(next[0]) {
+		return "", 0
+	}
+	v := next[0]
+	if s.isBool(key) {
+		return "", 0
+	}
+	if !v.IsFlag() || isNumber(v.Value) {
+		return v.Value, 1
+	}
+	return "", 0
+}
+
+// isTerminator reports whether the token is the -- ending the flags
+func isTerminator(t Token) bool {
+	return !t.Literal && t.Value == "--"
+}
+
+// isNumber reports whether s is a negative number, e.g. -5 or -.5,
+// that is a dash followed by a digit or by a dot and a digit
+func isNumber(s string) bool {
+	if !strings.HasPrefix(s, "-") {
+		return false
+	}
+	s = strings.TrimPrefix(s[1:], ".")
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
@@ -18,87 +18,134 @@ import (
 // command with key-value and key only flags
 // del -d dir -f filename -e
 //
-// command with flag value of several words
+// command with flag value of several words, which Parse joins with
+// spaces. ParseFlags, which the CLI uses to execute commands, gives a
+// flag a single word, so there the value must be quoted as below.
 // rem -d dir -f filename -e -m This is one
 //
 // command with flag value in quotation marks
 // add -d dir -f filename -e -m "This is one"
 //
+// command with flag value that contains dashes
+// add -m 'a -b c' -f my-file.txt
+//
+// command with positional arguments before the flags
+// get file1 file2 -v
+//
 // help command prints the same as command -h
 // help add
-//
-func Parse(cmd string) (string, map[string]string) {
-	cmdName := getCommand(cmd)
-	if cmdName == "help" {
-		cmdName = getNextCommand(cmd, cmdName)
-		cmd = cmdName + " -h"
+func Parse(cmd string) (*Result, error) {
+	tokens, err := Tokenize(cmd)
+	if err != nil {
+		return nil, err
 	}
-	flags := getFlags(cmd)
-	return cmdName, flags
+	return ParseTokens(tokens), nil
 }
 
-func getFlags(cmd string) map[string]string {
-	if strings.HasPrefix(cmd, "-") {
-		// add a space as prefix in order to use the split function
-		cmd = " " + cmd
+// ParseTokens is like Parse for a command line that is already split
+// into words, either by Tokenize or by the shell.
+func ParseTokens(tokens []Token) *Result {
+	r := &Result{
+		Command: getCommand(tokens),
+		Args:    getArgs(tokens),
 	}
-
-	f := strings.Split(cmd, " -")
-	flags := make(map[string]string)
-	for i := 1; i < len(f); i++ {
-		// this is the case that a command is passed with '--' as prefix
-		if strings.HasPrefix(f[i], "-") {
-			f[i] = strings.Replace(f[i], "-", "", 1)
+	if r.Command == "help" {
+		r.Command = getNextCommand(tokens)
+		if len(r.Args) > 0 {
+			r.Args = r.Args[1:]
 		}
-		flag := f[i]
-		k, v := getKeyValue(flag)
-		flags[k] = v
+		r.Flags = map[string]string{"h": ""}
+		r.Occurrences = []Flag{{Key: "h"}}
+		return r
 	}
-	return flags
+	r.Flags, r.Occurrences = getFlags(tokens)
+	return r
 }
 
-func getKeyValue(s string) (string, string) {
-	ff := strings.Split(s, " ")
-	if len(ff) == 1 {
-		return strings.Trim(ff[0], " "), ""
+// Result holds the parts of a parsed command line.
+type Result struct {
+	// Command is the first word of the line,
+	// empty when the line starts with a flag.
+	Command string
+	// Args are the words following the command up to the first flag.
+	Args []string
+	// Flags maps each flag key to its value. When a
+	// flag is repeated the last value is kept.
+	Flags map[string]string
+	// Occurrences holds every flag in the order they are
+	// passed, including the repeated ones.
+	Occurrences []Flag
+}
+
+// Flag is a flag key along with its value as passed on the command line.
+type Flag struct {
+	Key   string
+	Value string
+}
+
+// IsFlag reports whether the token is a flag key, that is an
+// unquoted word starting with '-' that is not a lone dash.
+func (t Token) IsFlag() bool {
+	return !t.Literal && len(t.Value) > 1 && strings.HasPrefix(t.Value, "-")
+}
+
+func getFlags(tokens []Token) (map[string]string, []Flag) {
+	flags := make(map[string]string)
+	var occurrences []Flag
+	add := func(key string, values []string) {
+		value := strings.Join(values, " ")
+		flags[key] = value
+		occurrences = append(occurrences, Flag{Key: key, Value: value})
 	}
 
-	if len(ff) > 2 {
-		key := strings.Trim(ff[0], " ")
-		val := ""
-		for i := 1; i < len(ff); i++ {
-			val += ff[i] + " "
+	key := ""
+	var values []string
+	inFlag := false
+	for _, t := range tokens {
+		if !t.IsFlag() {
+			if inFlag {
+				values = append(values, t.Value)
+			}
+			continue
+		}
+		if inFlag {
+			add(key, values)
 		}
-		val = strings.Trim(val, " ")
-		val = strings.Replace(val, "\"", "", -1)
-		return key, val
+		// this is the case that a command is passed with '--' as prefix
+		key = strings.TrimPrefix(t.Value[1:], "-")
+		values = values[:0]
+		inFlag = true
 	}
-	return strings.Trim(ff[0], " "), strings.Trim(ff[1], " ")
+	if inFlag {
+		add(key, values)
+	}
+	return flags, occurrences
 }
 
-func getCommand(cmd string) string {
-	ss := strings.Split(cmd, " ")
-	if strings.HasPrefix(ss[0], "-") {
+func getCommand(tokens []Token) string {
+	if len(tokens) == 0 || tokens[0].IsFlag() {
 		return ""
 	}
-	return ss[0]
+	return tokens[0].Value
 }
 
-func getNextCommand(cmd, current string) string {
-	ss := strings.Split(cmd, " ")
-	l := len(ss)
-	for i := 0; i < l; i++ {
-		if ss[i] != current {
-			continue
-		}
-		if i+1 >= l {
-			continue
+func getArgs(tokens []Token) []string {
+	if getCommand(tokens) == "" {
+		return nil
+	}
+	var args []string
+	for _, t := range tokens[1:] {
+		if t.IsFlag() {
+			break
 		}
+		args = append(args, t.Value)
+	}
+	return args
+}
 
-		if strings.HasPrefix(ss[i+1], "-") {
-			return ""
-		}
-		return ss[i+1]
+func getNextCommand(tokens []Token) string {
+	if len(tokens) < 2 || tokens[1].IsFlag() {
+		return ""
 	}
-	return ""
+	return tokens[1].Value
 }
@@ -4,7 +4,10 @@
 
 package parse
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestGetFlags(t *testing.T) {
 	var test = []struct {
@@ -18,7 +21,7 @@ func TestGetFlags(t *testing.T) {
 	}
 
 	for _, tt := range test {
-		flags := getFlags(tt.cmd)
+		flags, _ := getFlags(mustTokenize(t, tt.cmd))
 		if len(flags) != tt.numOfFlags {
 			t.Errorf("expected %d number of flags, instead got %d", tt.numOfFlags, len(flags))
 		}
@@ -47,7 +50,7 @@ func TestGetCommand(t *testing.T) {
 	}
 
 	for _, tt := range test {
-		cmd := getCommand(tt.line)
+		cmd := getCommand(mustTokenize(t, tt.line))
 		if cmd != tt.cmd {
 			t.Errorf("expected '%s', got '%s'", tt.cmd, cmd)
 		}
@@ -69,17 +72,44 @@ func BenchmarkParse(b *testing.B) {
 	}
 }
 
-func BenchmarkGetKeyValue(b *testing.B) {
-	s := "-d dir "
+func BenchmarkTokenize(b *testing.B) {
+	s := "add -d dir -f filename -e -m \"This is one\""
 	for n := 0; n < b.N; n++ {
-		getKeyValue(s)
+		Tokenize(s)
 	}
-	s = "-d dir  "
+	s = `add -m 'a -b c' -f my\ file.txt`
 	for n := 0; n < b.N; n++ {
-		getKeyValue(s)
+		Tokenize(s)
 	}
-	s = " -d dir"
-	for n := 0; n < b.N; n++ {
-		getKeyValue(s)
+}
+
+func TestGetFlagsWithDashes(t *testing.T) {
+	var test = []struct {
+		cmd   string
+		flags map[string]string
+	}{
+		{`add -m "a -b c"`, map[string]string{"m": "a -b c"}},
+		{`add -m 'a -b c' -e`, map[string]string{"m": "a -b c", "e": ""}},
+		{`get -f my-file.txt --dir /tmp/a-b`, map[string]string{"f": "my-file.txt", "dir": "/tmp/a-b"}},
+		{`get -f "-x.txt"`, map[string]string{"f": "-x.txt"}},
+		{`get -f \-x.txt`, map[string]string{"f": "-x.txt"}},
+		{`get -f -`, map[string]string{"f": "-"}},
+		{`get -m ""`, map[string]string{"m": ""}},
+	}
+
+	for _, tt := range test {
+		flags, _ := getFlags(mustTokenize(t, tt.cmd))
+		if !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("%s: expected %v, got %v", tt.cmd, tt.flags, flags)
+		}
+	}
+}
+
+func mustTokenize(t *testing.T, line string) []Token {
+	t.Helper()
+	tokens, err := Tokenize(line)
+	if err != nil {
+		t.Fatalf("failed to tokenize '%s': %v", line, err)
 	}
+	return tokens
 }
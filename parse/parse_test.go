@@ -5,6 +5,8 @@
 package parse_test
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/RomanosTrechlis/go-icls/parse"
@@ -25,10 +27,16 @@ func TestParse(t *testing.T) {
 		{"help get", "get", 1, 0}, // actual command is "get -h"
 		{"help -h", "", 1, 0},     // actual command is "-h"
 		{"-h", "", 1, 0},
+		{"add -m \"a -b c\" -f my-file.txt", "add", 2, 2},
+		{"add -m 'it''s' -e", "add", 2, 1},
 	}
 
 	for _, tt := range test {
-		cmdName, flags := parse.Parse(tt.cmd)
+		r, err := parse.Parse(tt.cmd)
+		if err != nil {
+			t.Fatalf("failed to parse '%s': %v", tt.cmd, err)
+		}
+		cmdName, flags := r.Command, r.Flags
 		if cmdName != tt.cmdName {
 			t.Errorf("expected command name to be '%s', instead got '%s'", tt.cmdName, cmdName)
 		}
@@ -48,3 +56,98 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{`add -m "open`, `add -m 'open`, `add -m trailing\`} {
+		if _, err := parse.Parse(line); err == nil {
+			t.Errorf("expected error for '%s', got none", line)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	var test = []struct {
+		cmd     string
+		cmdName string
+		args    []string
+	}{
+		{"get", "get", nil},
+		{"get myfile -v", "get", []string{"myfile"}},
+		{"get 'my file' other -v -f x", "get", []string{"my file", "other"}},
+		{"get -v myfile", "get", nil},
+		{"-v myfile", "", nil},
+		{"help remote add", "remote", []string{"add"}},
+	}
+
+	for _, tt := range test {
+		r, err := parse.Parse(tt.cmd)
+		if err != nil {
+			t.Fatalf("failed to parse '%s': %v", tt.cmd, err)
+		}
+		if r.Command != tt.cmdName {
+			t.Errorf("expected command name to be '%s', instead got '%s'", tt.cmdName, r.Command)
+		}
+		if !reflect.DeepEqual(r.Args, tt.args) {
+			t.Errorf("%s: expected args %v, instead got %v", tt.cmd, tt.args, r.Args)
+		}
+	}
+}
+
+func TestParseOccurrences(t *testing.T) {
+	r, err := parse.Parse("run -t a -D k=v --tag 'b c' -t d -v")
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	expected := []parse.Flag{{Key: "t", Value: "a"}, {Key: "D", Value: "k=v"}, {Key: "tag", Value: "b c"},
+		{Key: "t", Value: "d"}, {Key: "v"}}
+	if !reflect.DeepEqual(r.Occurrences, expected) {
+		t.Errorf("expected occurrences %v, instead got %v", expected, r.Occurrences)
+	}
+	if r.Flags["t"] != "d" {
+		t.Errorf("expected the last value of a repeated flag, instead got '%s'", r.Flags["t"])
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	spec := parse.Spec{
+		Known: func(key string) bool {
+			return strings.Contains("abvognif", key) && len(key) == 1 || key == "name"
+		},
+		Bool: func(key string) bool {
+			return strings.Contains("abvinf", key) && len(key) == 1
+		},
+	}
+
+	var test = []struct {
+		line  string
+		flags map[string]string
+		args  []string
+	}{
+		{"--name=x y", map[string]string{"name": "x"}, []string{"y"}},
+		{"--name x y", map[string]string{"name": "x"}, []string{"y"}},
+		{"-name x", map[string]string{"name": "x"}, nil},
+		{"-abv", map[string]string{"a": "", "b": "", "v": ""}, nil},
+		{"-vofile", map[string]string{"v": "", "o": "file"}, nil},
+		{"-ofile -v x", map[string]string{"o": "file", "v": ""}, []string{"x"}},
+		{"-o -v", map[string]string{"o": "", "v": ""}, nil},
+		{"-a true --b=false", map[string]string{"a": "", "b": "false"}, []string{"true"}},
+		{"x -inf", map[string]string{"i": "", "n": "", "f": ""}, []string{"x"}},
+		{"-g -5 -o -.5 -10", map[string]string{"g": "-5", "o": "-.5"}, []string{"-10"}},
+		{"x -v -- -a --name y", map[string]string{"v": ""}, []string{"x", "-a", "--name", "y"}},
+		{"-o '--' -- --", map[string]string{"o": "--"}, []string{"--"}},
+		{"-la --color always", map[string]string{"la": "", "color": "always"}, nil},
+	}
+	for _, tt := range test {
+		tokens, err := parse.Tokenize(tt.line)
+		if err != nil {
+			t.Fatalf("failed to tokenize '%s': %v", tt.line, err)
+		}
+		r := parse.ParseFlags(tokens, spec)
+		if !reflect.DeepEqual(r.Flags, tt.flags) {
+			t.Errorf("%s: expected flags %v, instead got %v", tt.line, tt.flags, r.Flags)
+		}
+		if !reflect.DeepEqual(r.Args, tt.args) {
+			t.Errorf("%s: expected args %v, instead got %v", tt.line, tt.args, r.Args)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token is a single word of a command line.
+type Token struct {
+	// Value is the word with its quotes and escapes removed.
+	Value string
+	// Literal is true when the word begins with a quoted or escaped
+	// character, e.g. "-b" or \-b, which means it is never a flag.
+	Literal bool
+}
+
+// Tokenize splits a command line into words the way a POSIX shell does.
+//
+// Words are separated by unquoted white space. Single quotes preserve
+// everything up to the closing quote. Double quotes preserve everything
+// except the escapes \" and \\. Outside of quotes a backslash escapes
+// the character that follows it. Quoted parts may be adjacent to
+// unquoted ones, so --name="a b" is the single word --name=a b.
+func Tokenize(line string) ([]Token, error) {
+	l := scan(line)
+	if l.quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", l.quote)
+	}
+	if l.escape >= 0 {
+		return nil, fmt.Errorf("trailing backslash at position %d", l.escape)
+	}
+	return l.tokens, nil
+}
+
+// TokenizePartial is like Tokenize for a line that may end in the middle
+// of a word, as when the user is still typing it. It returns the complete
+// words, the unfinished last word, empty when the line ends with white
+// space, and the byte offset where the last word begins.
+func TokenizePartial(line string) ([]Token, Token, int) {
+	l := scan(line)
+	if !l.open {
+		return l.tokens, Token{}, len(line)
+	}
+	n := len(l.tokens)
+	return l.tokens[:n-1], l.tokens[n-1], l.start
+}
+
+// lexed is the outcome of scanning a line
+type lexed struct {
+	tokens []Token
+	// open is true when the line ends inside the last word
+	open bool
+	// start is the byte offset of the last word
+	start int
+	// quote is the quote left open at the end of the line
+	quote rune
+	// escape is the position of a trailing backslash, -1 if there is none
+	escape int
+}
+
+func scan(line string) lexed {
+	var (
+		l       = lexed{escape: -1}
+		buf     strings.Builder
+		literal bool
+	)
+	runes := []rune(line)
+	// begin marks the start of a word, remembering whether
+	// its first character was quoted or escaped
+	begin := func(i int, quoted bool) {
+		if !l.open {
+			l.open = true
+			l.start = len(string(runes[:i]))
+			literal = quoted
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case l.quote == '\'':
+			if r == '\'' {
+				l.quote = 0
+				continue
+			}
+			buf.WriteRune(r)
+		case l.quote == '"':
+			if r == '"' {
+				l.quote = 0
+				continue
+			}
+			if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				r = runes[i]
+			}
+			buf.WriteRune(r)
+		case r == '\'' || r == '"':
+			begin(i, true)
+			l.quote = r
+		case r == '\\':
+			begin(i, true)
+			if i+1 >= len(runes) {
+				l.escape = i
+				continue
+			}
+			i++
+			buf.WriteRune(runes[i])
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if l.open {
+				l.tokens = append(l.tokens, Token{Value: buf.String(), Literal: literal})
+				buf.Reset()
+				l.open = false
+			}
+		default:
+			begin(i, false)
+			buf.WriteRune(r)
+		}
+	}
+	if l.open {
+		l.tokens = append(l.tokens, Token{Value: buf.String(), Literal: literal})
+	}
+	return l
+}
+
+// Quote returns s quoted so that Tokenize reads it back as a single word.
+// Words that need no quoting are returned unchanged.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n\r'\"\\") {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// Join quotes every word and joins them with spaces, the
+// reverse of Tokenize.
+func Join(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = Quote(w)
+	}
+	return strings.Join(quoted, " ")
+}
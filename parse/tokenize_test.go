@@ -0,0 +1,99 @@
+// Copyright 2017 The go-icls Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package parse_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/RomanosTrechlis/go-icls/parse"
+)
+
+func TestTokenize(t *testing.T) {
+	var test = []struct {
+		line   string
+		tokens []parse.Token
+		err    bool
+	}{
+		{"", nil, false},
+		{"  get   -f  file ", []parse.Token{{Value: "get"}, {Value: "-f"}, {Value: "file"}}, false},
+		{`add -m "a -b c"`, []parse.Token{{Value: "add"}, {Value: "-m"}, {Value: "a -b c", Literal: true}}, false},
+		{`add -m 'say "hi"'`, []parse.Token{{Value: "add"}, {Value: "-m"}, {Value: `say "hi"`, Literal: true}}, false},
+		{`add -m "say \"hi\" \\ \n"`, []parse.Token{{Value: "add"}, {Value: "-m"}, {Value: `say "hi" \ \n`, Literal: true}}, false},
+		{`get my\ file`, []parse.Token{{Value: "get"}, {Value: "my file"}}, false},
+		{`get \-f`, []parse.Token{{Value: "get"}, {Value: "-f", Literal: true}}, false},
+		{`get --name="a b"`, []parse.Token{{Value: "get"}, {Value: "--name=a b"}}, false},
+		{`get '' ""`, []parse.Token{{Value: "get"}, {Value: "", Literal: true}, {Value: "", Literal: true}}, false},
+		{`get 'a'"b"c`, []parse.Token{{Value: "get"}, {Value: "abc", Literal: true}}, false},
+		{`get "open`, nil, true},
+		{`get 'open`, nil, true},
+		{`get open\`, nil, true},
+	}
+
+	for _, tt := range test {
+		tokens, err := parse.Tokenize(tt.line)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected error for '%s', got %v", tt.line, tokens)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expected no error for '%s', got '%v'", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(tokens, tt.tokens) {
+			t.Errorf("%s: expected %v, got %v", tt.line, tt.tokens, tokens)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	var test = [][]string{
+		{"a", "b"},
+		{"my file", "it's", "-x", ""},
+		{`back\slash`, `"quoted"`},
+	}
+
+	for _, words := range test {
+		tokens, err := parse.Tokenize(parse.Join(words))
+		if err != nil {
+			t.Fatalf("failed to tokenize joined %v: %v", words, err)
+		}
+		got := make([]string, len(tokens))
+		for i, tok := range tokens {
+			got[i] = tok.Value
+		}
+		if !reflect.DeepEqual(got, words) {
+			t.Errorf("expected %v, got %v", words, got)
+		}
+	}
+}
+
+func TestTokenizePartial(t *testing.T) {
+	var test = []struct {
+		line   string
+		words  int
+		last   string
+		offset int
+	}{
+		{"", 0, "", 0},
+		{"ge", 0, "ge", 0},
+		{"get ", 1, "", 4},
+		{"get -f", 1, "-f", 4},
+		{`get -f "my fi`, 2, "my fi", 7},
+		{`get -f my\ fi`, 2, "my fi", 7},
+		{`get -f my\`, 2, "my", 7},
+		{"ü -", 1, "-", 3},
+	}
+
+	for _, tt := range test {
+		tokens, last, offset := parse.TokenizePartial(tt.line)
+		if len(tokens) != tt.words || last.Value != tt.last || offset != tt.offset {
+			t.Errorf("%s: expected %d words, '%s' at %d, got %d words, '%s' at %d",
+				tt.line, tt.words, tt.last, tt.offset, len(tokens), last.Value, offset)
+		}
+	}
+}